@@ -0,0 +1,176 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Args parses GNU-style command line arguments (without the program name)
+// and coerces them into the fields of the structure pointed to by 'to'.
+// Long options are derived from field names as per Struct, eg field DryRun
+// is set by "--dry-run", unless named by a tag such as `coerce:"--dry"`;
+// single-letter fields, and fields tagged `coerce:",short=n"`, are also set
+// by "-n", and `alias=` tag options give further long options.  Supported
+// forms are
+//
+//	--name=value  --name value  -n value  -nvalue
+//	--flag  -f  -abc (several bool flags at once)
+//
+// Bool fields take no value unless given with "=", eg "--verbose=false".
+// Options given more than once accumulate a list of values for slice
+// fields (and scalar fields with a `reduce=` tag), or of "key=value" pairs
+// for map fields; otherwise the last value wins.
+//
+// Other arguments, and all those after "--", are positional and are
+// assigned to fields tagged `coerce:",pos"` as per Positional; it is an
+// error if there are more than the struct accepts.
+func Args(to interface{}, args []string) error {
+
+	pt := reflect.ValueOf(to)
+	if pt.Kind() != reflect.Ptr || pt.Elem().Kind() != reflect.Struct {
+		return errorf(ErrCannotSet, "expected *struct for 'to', got %v", pt.Kind())
+	}
+
+	long, short, maxPos := argFlags(pt.Elem().Type())
+	m := map[string]interface{}{}
+	var positional []string
+	set := func(f reflect.StructField, value string) {
+		key := "--" + f.Name
+		tag := parseTag(f)
+		if tag.name != "" {
+			key = tag.name
+		}
+		_, reduce := tag.option("reduce")
+		if prev, ok := m[key]; ok && (reduce || f.Type.Kind() == reflect.Slice || f.Type.Kind() == reflect.Map) {
+			if list, ok := prev.([]string); ok {
+				m[key] = append(list, value)
+			} else {
+				m[key] = []string{prev.(string), value}
+			}
+			return
+		}
+		m[key] = value
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			f, ok := long[name]
+			if !ok {
+				return fmt.Errorf("unknown option --%s", name)
+			}
+			if !hasValue {
+				if f.Type.Kind() == reflect.Bool {
+					value = "true"
+				} else if i+1 < len(args) {
+					i++
+					value = args[i]
+				} else {
+					return fmt.Errorf("option --%s requires a value", name)
+				}
+			}
+			set(f, value)
+
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// one or more short options, the last of which may take a value
+			for j := 1; j < len(arg); j++ {
+				f, ok := short[arg[j]]
+				if !ok {
+					return fmt.Errorf("unknown option -%c", arg[j])
+				}
+				if f.Type.Kind() == reflect.Bool {
+					set(f, "true")
+					continue
+				}
+				value := arg[j+1:]
+				if value == "" {
+					if i+1 >= len(args) {
+						return fmt.Errorf("option -%c requires a value", arg[j])
+					}
+					i++
+					value = args[i]
+				}
+				set(f, value)
+				break
+			}
+
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) > maxPos {
+		return fmt.Errorf("unexpected argument %q", positional[maxPos])
+	}
+	for k, v := range positionalMap(positional) {
+		m[k] = v
+	}
+
+	return Struct(to, m, "--%s")
+}
+
+// argFlags maps the long and short option names accepted by Args to the
+// fields of struct type t, and returns the number of positional arguments
+// the struct accepts
+func argFlags(t reflect.Type) (map[string]reflect.StructField, map[byte]reflect.StructField, int) {
+	long := map[string]reflect.StructField{}
+	short := map[byte]reflect.StructField{}
+	maxPos, nextPos := 0, 0
+	for _, fl := range defaultDecoder.fields(t) {
+		f := fl.StructField
+		if n, ok := fl.tag.position(&nextPos); ok {
+			if isList(f.Type) {
+				maxPos = math.MaxInt
+			} else if n >= maxPos {
+				maxPos = n + 1
+			}
+			continue
+		}
+		if name := fl.tag.name; name != "" && !isPointer(name) {
+			// the tag names the option
+			long[strings.TrimLeft(name, "-")] = f
+		} else {
+			for _, name := range nameVariants(f.Name) {
+				long[name] = f
+			}
+		}
+		for _, alias := range fl.tag.aliases {
+			long[strings.TrimLeft(alias, "-")] = f
+		}
+		if len(f.Name) == 1 {
+			short[f.Name[0]] = f
+		}
+		if s, ok := fl.tag.option("short"); ok && len(s) == 1 {
+			short[s[0]] = f
+		}
+	}
+	return long, short, maxPos
+}
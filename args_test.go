@@ -0,0 +1,41 @@
+package coerce
+
+import "testing"
+
+func Test_Args_positional(t *testing.T) {
+	type cp struct {
+		Recursive bool     `coerce:",short=r"`
+		Dest      string   `coerce:",pos=0"`
+		Sources   []string `coerce:",pos"`
+	}
+
+	var c cp
+	err := Args(&c, []string{"dest", "-r", "a", "--", "-b"})
+	report(err, cp{true, "dest", []string{"a", "-b"}}, c, t)
+
+	type one struct {
+		Name string `coerce:",pos"`
+	}
+	var o one
+	if err := Args(&o, []string{"a", "b"}); err == nil {
+		t.Errorf("expected error for surplus argument")
+	}
+
+	err = Positional(&o, []string{"x"})
+	report(err, one{"x"}, o, t)
+}
+
+func Test_Args_tag_name(t *testing.T) {
+	type opts struct {
+		DryRun bool `coerce:"--dry"`
+		Count  int  `coerce:"file.count"`
+	}
+
+	var o opts
+	err := Args(&o, []string{"--dry", "--file.count", "2"})
+	report(err, opts{true, 2}, o, t)
+
+	if err := Args(&o, []string{"--dry-run"}); err == nil {
+		t.Errorf("expected error for untagged option name")
+	}
+}
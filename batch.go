@@ -0,0 +1,64 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatchError reports the records which failed to decode in a call to
+// Structs, keyed by their index in the input
+type BatchError map[int]error
+
+// Error lists the failed records in index order, one per line
+func (e BatchError) Error() string {
+	idx := make([]int, 0, len(e))
+	for i := range e {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+
+	lines := make([]string, len(idx))
+	for n, i := range idx {
+		lines[n] = fmt.Sprintf("record %d: %v", i, e[i])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Structs coerces each map in 'from' into a T (which must be a struct
+// type) as per Struct, returning a slice the same length as 'from'.  If any
+// records fail, the error is a BatchError and the corresponding elements
+// hold whatever could be decoded.
+func Structs[T any](from []map[string]interface{}, formats ...string) ([]T, error) {
+	d := NewDecoder(Formats(formats...))
+	result := make([]T, len(from))
+	errs := BatchError{}
+	for i, m := range from {
+		if err := d.Decode(&result[i], m); err != nil {
+			errs[i] = err
+		}
+	}
+	if len(errs) > 0 {
+		return result, errs
+	}
+	return result, nil
+}
@@ -0,0 +1,23 @@
+package coerce
+
+import "testing"
+
+func Test_Structs(t *testing.T) {
+	type rec struct {
+		ID   int
+		Name string
+	}
+
+	recs, err := Structs[rec]([]map[string]interface{}{
+		{"--id": "1", "--name": "one"},
+		{"--id": "x", "--name": "two"},
+		{"--id": "3"},
+	}, "--%s")
+
+	report(nil, []rec{{1, "one"}, {0, "two"}, {3, ""}}, recs, t)
+
+	be, ok := err.(BatchError)
+	if !ok || len(be) != 1 || be[1] == nil {
+		t.Errorf("expected BatchError for record 1, got %#v", err)
+	}
+}
@@ -0,0 +1,62 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import "reflect"
+
+// FlagContext is the subset of the urfave/cli context API (*cli.Context in
+// v2, *cli.Command in v3) needed by CLI; declaring it here avoids a
+// dependency on urfave/cli
+type FlagContext interface {
+	FlagNames() []string
+	Value(name string) interface{}
+}
+
+// CLI coerces the flags set in a urfave/cli context into the fields of the
+// structure pointed to by 'to', matching flag names (eg "dry-run") to
+// field names (eg DryRun) as per Struct.  Slice flags (cli.StringSlice,
+// cli.IntSlice etc) are unwrapped into plain slices.
+func CLI(to interface{}, c FlagContext) error {
+	m := map[string]interface{}{}
+	for _, name := range c.FlagNames() {
+		m[name] = unwrapFlagValue(c.Value(name))
+	}
+	return Struct(to, m)
+}
+
+// unwrapFlagValue returns the plain slice held by urfave/cli slice flag
+// values, which expose it via a Value() method (on the pointer receiver),
+// or v itself otherwise
+func unwrapFlagValue(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return v
+	}
+	if rv.Kind() != reflect.Ptr {
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		rv = p
+	}
+	m := rv.MethodByName("Value")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 || m.Type().Out(0).Kind() != reflect.Slice {
+		return v
+	}
+	return m.Call(nil)[0].Interface()
+}
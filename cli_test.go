@@ -0,0 +1,43 @@
+package coerce
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeSlice mimics cli.StringSlice, whose contents are exposed by a
+// pointer-receiver Value method
+type fakeSlice struct{ s []string }
+
+func (f *fakeSlice) Value() []string { return f.s }
+
+type fakeContext map[string]interface{}
+
+func (c fakeContext) FlagNames() []string {
+	var names []string
+	for n := range c {
+		names = append(names, n)
+	}
+	return names
+}
+
+func (c fakeContext) Value(name string) interface{} { return c[name] }
+
+func Test_CLI(t *testing.T) {
+	type opts struct {
+		DryRun  bool
+		Hosts   []string
+		Timeout time.Duration
+		Port    int
+	}
+
+	var o opts
+	err := CLI(&o, fakeContext{
+		"dry-run": true,
+		"hosts":   fakeSlice{[]string{"a", "b"}},
+		"timeout": 5 * time.Second,
+		"port":    "8080",
+	})
+
+	report(err, opts{true, []string{"a", "b"}, 5 * time.Second, 8080}, o, t)
+}
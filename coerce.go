@@ -23,12 +23,15 @@ package coerce
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-	"time"
-	"unsafe"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Struct attempts to unmarshall the values in 'from' into the fields
@@ -36,10 +39,15 @@ import (
 // map keys.  Optional format strings can be used to morph the field
 // names into keys, eg "--%s" will map field "foo" to key "--foo".
 // If more than one format is supplied, these will be tried in order
-// until the first matching key is found.
+// until the first matching key is found.  For each format the field name
+// is tried as-is, then lowercased, hyphenated and underscored (eg "MapDown"
+// matches "map-down").  If several different keys match, the first in that
+// order of precedence is used; see ErrorOnAmbiguousKeys and Warnings.
 // When coercing from string to any integer types, if the string ends
 // with B|K|M|G|T (case-insensitive) then these will be interpreted
-// as multipliers of 1, 1024, etc.
+// as multipliers of 1, 1024, etc.  Strings with a 0x, 0o or 0b prefix
+// are read as hex, octal or binary (eg "0o755"); other leading zeros are
+// not significant.
 //
 // Example:
 //	type x struct{
@@ -59,184 +67,1018 @@ import (
 //	err := coerce.Struct(&myx, mymap, "--%s", "-%s")
 //	fmt.Println(err, myx) // <nil> {[5 12 512] true hello}
 //
-// Note: coercing unexported fields uses 'unsafe' pointers
+// Integer fields also accept sizes with a unit suffix, eg "0.5k" (512),
+// "10MB" (10,000,000) or "4GiB"; see SIByteUnits.
+// time.Duration fields accept time.ParseDuration strings extended with
+// days and weeks, eg "2d" or "1w3d12h".  Float fields accept percentages,
+// eg "75%" gives 0.75; see KeepPercent.  Numeric fields count repeated
+// flags, eg [true true] (as docopt may give for "-v -v") gives 2 and true
+// gives 1; conversely bool fields take such counts, being true if above
+// zero (bar with StrictBool).
+//
+// time.Time (and *time.Time) fields tagged with a layout, eg
+//
+//	Day     time.Time `coerce:",layout=2006-01-02"`
+//	Created time.Time `coerce:",layout=RFC3339"`
+//	Expires time.Time `coerce:",layout=unixmilli"`
+//
+// parse strings with that layout alone, in place of the Decoder's
+// TimeLayouts and the defaults; layouts are as per Map, so that its output
+// can be passed back to Struct.
+//
+// Conversion errors for several fields are combined into one error, one
+// line per field in struct field order.
+//
+// A slice value normally maps to a single (non-slice) field only if it has
+// exactly one element.  Tagging the field with a reducer, eg
+//
+//	Delay time.Duration `coerce:",reduce=sum"`
+//
+// instead combines all the elements; reducers are sum, min, max, first,
+// last and join (which joins strings with "," or the `sep=` tag option).
+//
+// Conversely a string value for a slice field is split on "," (or the
+// Separator option, or the field's `sep=` tag option) and each element,
+// trimmed of surrounding spaces, coerced in turn, eg "a, b,c" for a
+// []string field gives {"a", "b", "c"}; an empty string gives an empty
+// slice.  Array fields (eg [4]byte) are filled likewise from slices or
+// split strings, which must have exactly as many elements.
+//
+// Map fields likewise take lists of "key=value" strings, or strings of
+// them split as above, eg "env=prod, tier=web" for a map[string]string
+// field; see KeyValueSeparator, or the field's `kvsep=` tag option.
+//
+// []byte (and [N]byte) fields tagged `coerce:",encoding=base64"` decode
+// string values from base64 (standard or URL alphabet, padding optional),
+// or with `encoding=hex` from hex, rather than taking their bytes as-is
+// (`encoding=raw`, the default for []byte); Map encodes them likewise.
+//
+// Fields tagged `coerce:",glob"` (typically []string) have any file name
+// patterns in their values expanded as per filepath.Glob; patterns which
+// match nothing are kept as-is, as shells do.
+//
+// A tag name overrides the field name and formats, giving the key to use
+// verbatim, eg
+//
+//	DryRun bool `coerce:"--dry-run"`
+//	Count  int  `coerce:"file.count"`
+//
+// Further keys may be given by `alias=` tag options, tried in order if
+// the field's own key is absent, so that options can be renamed without
+// breaking old configurations, eg
+//
+//	Timeout time.Duration `coerce:"timeout,alias=timeout_secs,alias=ttl"`
+//
+// Fields tagged `coerce:"-"` are skipped, even by Map, Args and the
+// like (use `coerce:"-,"` for the key "-" itself).  Fields tagged
+// `coerce:",required"` fail, with an error wrapping ErrRequired, if no key
+// matches them; see also ErrorOnMissingKeys.  Fields tagged eg
+// `coerce:",default=8080"` are coerced from the default (as a string) if
+// no key matches them or the value is nil.
+//
+// Keys may also be JSON Pointers (RFC 6901) addressing values in nested
+// maps and slices, either via formats (eg "/server/%s") or per field by a
+// tag such as `coerce:"/servers/0/host"`.  Keys present literally in the
+// map take precedence over pointer lookups.
+//
+// Fields tagged `coerce:",pos=N"` take the positional value held under key
+// "N" (eg "0" for the first) regardless of formats; a bare `pos` takes the
+// position after the previous positional field.  A slice field so tagged
+// also collects all further positions.  See also Positional and Args.
+//
+// If any field fails, the error is a *DecodeError recording which fields
+// were assigned and which failed; failed fields are left unchanged, so the
+// partially-populated struct remains usable.  Each failure is a
+// *FieldError, which errors.As can extract; failures within nested
+// structs, slices and maps are located by the full path of the field, eg
+// "Server.Listeners[2].Port".
+//
+// String values for types which implement encoding.TextUnmarshaler are
+// passed to UnmarshalText, bar those (such as time.Time and net.IP) which
+// coerce parses more leniently itself.  Failing all else, string values
+// for types which implement json.Unmarshaler are passed to UnmarshalJSON,
+// quoted as a JSON string unless already valid JSON.
+//
+// Pointer fields (eg *int, *MyStruct) are allocated when a value is
+// present and left nil otherwise, so they can model optional settings.
+//
+// The database/sql nullable types (sql.NullString, sql.NullInt64, sql.Null[T]
+// etc) are coerced into their value and marked valid, or set to NULL by
+// nil values, so that row maps can be decoded directly; other types which
+// implement sql.Scanner are passed the value (nil included) to Scan.
+//
+// The fields of embedded structs (bar types such as time.Time which coerce
+// parses as a whole), and of struct fields tagged `coerce:",squash"`, are
+// treated as fields of the outer struct, as if flattened into it.
+//
+// Struct fields are filled from nested maps (eg decoded JSON or YAML
+// objects) field by field, with the field names as keys; fields missing
+// from the nested map keep their values.  Nested maps may have interface{}
+// keys, as decoded by gopkg.in/yaml.v2, whose keys are stringified; see
+// StringKeys for converting such a map for the top level.
+//
+// A map field tagged `coerce:",remain"` (typically map[string]interface{})
+// collects the keys which match no other field, and their values, eg to
+// pass unknown options on to a plugin; ErrorOnUnusedKeys then has nothing
+// to report.
+//
+// Fields tagged `coerce:",raw"` receive the original map value without
+// any conversion, analogous to json.RawMessage; they are typically
+// declared as interface{} so that parsing can be deferred or customised.
+//
+// Unexported fields are set by calling setter methods where the struct
+// has them, eg SetName(string) on *T for field name, so that any
+// invariants they maintain are kept.
+//
+// Note: coercing unexported fields otherwise uses 'unsafe' pointers, unless
+// built with the coerce_safe tag, in which case they can't be set (or read
+// by Map); see also SkipUnexported.
 //
 func Struct(to interface{}, from map[string]interface{}, formats ...string) error {
+	return NewDecoder(Formats(formats...)).Decode(to, from)
+}
 
-	// parse errors are accumulated into errstr
-	errstr := ""
+// unmarshallStruct coerces the values in 'from' into the fields of the
+// addressable struct vt
+func (d *Decoder) unmarshallStruct(vt reflect.Value, from map[string]interface{}, formats []string) error {
+	return d.decodeStruct(vt, from, formats, nil, nil)
+}
 
-	// get target as reflect.Value and check kind:
-	pt := reflect.ValueOf(to)
-	vt := reflect.Indirect(pt)
-	if vt.Kind() != reflect.Struct || pt.Kind() != reflect.Ptr {
-		return fmt.Errorf("expected *struct for 'to', got %v", pt.Kind())
+// decodeStruct is unmarshallStruct, also recording what was done in md if
+// that is non-nil.  If keys is non-nil it holds the candidate keys for
+// each of d.fields(vt.Type()), as precomputed by Compile.
+func (d *Decoder) decodeStruct(vt reflect.Value, from map[string]interface{}, formats []string, md *Metadata, keys [][]string) error {
+
+	// parse errors are accumulated into errs, and field names into
+	// assigned or failed, using pooled storage
+	sc := getScratch()
+	defer sc.release()
+	errs, assigned, failed, used := sc.errs, sc.assigned, sc.failed, sc.used
+	defer func() {
+		sc.errs, sc.assigned, sc.failed, sc.used = errs, assigned, failed, used
+	}()
+	nextPos := 0 // index for the next `pos` field
+
+	var folded map[string][]string // keys by foldKey, if FoldKeys is set
+	var sorted []string            // keys in order, if MatchKeys is set
+
+	// fields with no key take their `default=` tag option, if any, and are
+	// otherwise left alone unless ErrorOnMissingKeys is set or they are
+	// tagged as required
+	missing := func(name string, tag fieldTag, err error) (interface{}, bool) {
+		if md != nil {
+			md.MissingFields = append(md.MissingFields, name)
+		}
+		if def, ok := tag.option("default"); ok {
+			return def, true
+		}
+		if _, required := tag.option("required"); required {
+			err = fmt.Errorf("%w: %w", ErrRequired, err)
+		} else if !d.errorOnMissing {
+			return nil, false
+		}
+		errs = append(errs, &FieldError{Field: name, Path: name, Err: err})
+		failed = append(failed, name)
+		return nil, false
 	}
 
-	// iterate over struct fields
-	for i := 0; i < vt.NumField(); i++ {
+	// iterate over struct fields, including those of squashed structs
+	var remain *field // the field collecting unused keys, if any
+	fields := d.fields(vt.Type())
+	for i, fl := range fields {
+		f, tag := fl.StructField, fl.tag
 
-		// get field type and pointer to value
-		f := vt.Type().Field(i)
-		vf := vt.Field(i)
-		if !vf.CanSet() {
-			// use 'unsafe' workaround for unexported fields:
-			if string(f.Name[0]) == strings.ToLower(string(f.Name[0])) {
-				pu := unsafe.Pointer(vf.Addr().Pointer())
-				vf = reflect.Indirect(reflect.NewAt(vf.Type(), pu))
+		if _, ok := tag.option("remain"); ok {
+			// filled from the unused keys once the other fields are done
+			r := fl
+			remain = &r
+			continue
+		}
+
+		var key string
+		var v interface{}
+		var defaulted bool // v is the tag's default
+		if n, ok := tag.position(&nextPos); ok {
+			// positional fields use index keys
+			var found bool
+			key = strconv.Itoa(n)
+			if v, found = positionalVal(from, n, isList(f.Type)); !found {
+				if v, defaulted = missing(f.Name, tag, errorf(ErrKeyNotFound, "[%s] not found in map", key)); !defaulted {
+					continue
+				}
+			} else if list, ok := v.([]interface{}); ok && isList(f.Type) {
+				for j := range list {
+					used = append(used, strconv.Itoa(n+j))
+				}
+			} else {
+				used = append(used, key)
 			}
-			if !vf.CanSet() {
-				errstr += "field " + f.Name + "not setable\n"
-				continue
+		} else if tag.name != "" {
+			// key named by the tag, or a JSON Pointer into nested maps
+			var found bool
+			key = tag.name
+			if v, found = lookupKey(from, key); !found {
+				key, v, found = aliasKey(from, tag.aliases)
+			}
+			if !found && d.nestSep != "" && d.nestable(f.Type) {
+				if k, sub, flat := d.nestedKeys(from, []string{tag.name}, d.siblingKeys(fields, i, formats, keys)); len(flat) > 0 {
+					key, v, found = k, sub, true
+					used = append(used, flat...)
+				}
+			}
+			if !found {
+				if v, defaulted = missing(f.Name, tag, errorf(ErrKeyNotFound, "[%s] not found in map", strings.Join(append([]string{tag.name}, tag.aliases...), "|"))); !defaulted {
+					continue
+				}
+			} else {
+				used = append(used, topKey(from, key))
+			}
+		} else {
+			// look for field name in map keys
+			var others []string
+			var err error
+			if keys != nil {
+				key, others, err = sc.findKey(from, keys[i])
+			} else if d.keyNames != nil {
+				key, others, err = sc.findKey(from, d.keyNames(f.Name))
+			} else {
+				key, others, err = sc.findVal(f.Name, from, formats)
+			}
+			if err != nil && len(tag.aliases) > 0 {
+				if k, _, found := aliasKey(from, tag.aliases); found {
+					key, others, err = k, nil, nil
+				}
+			}
+			if err != nil && d.foldKeys {
+				if folded == nil {
+					folded = foldIndex(from)
+				}
+				key, others, err = foldedKey(f.Name, folded, formats, err)
+			}
+			if err != nil && d.matchKey != nil {
+				if sorted == nil {
+					sorted = sortedStrings(from)
+				}
+				key, others, err = matchedKey(f.Name, sorted, d.matchKey, err)
+			}
+			nested := false // v gathers flat keys, as per NestedKeys
+			if err != nil && d.nestSep != "" && d.nestable(f.Type) {
+				prefixes := candidateKeys(f.Name, formats)
+				if keys != nil {
+					prefixes = keys[i]
+				} else if d.keyNames != nil {
+					prefixes = d.keyNames(f.Name)
+				}
+				if k, sub, flat := d.nestedKeys(from, prefixes, d.siblingKeys(fields, i, formats, keys)); len(flat) > 0 {
+					key, v, err, nested = k, sub, nil, true
+					used = append(used, flat...)
+				}
+			}
+			if err != nil {
+				if v, defaulted = missing(f.Name, tag, err); !defaulted {
+					continue
+				}
+			} else if !nested {
+				used = append(used, topKey(from, key))
+				for _, o := range others {
+					used = append(used, topKey(from, o))
+				}
+
+				if len(others) > 0 {
+					amb := &FieldError{Field: f.Name, Path: f.Name, Type: f.Type, Key: key,
+						Err: fmt.Errorf("ambiguous keys %q and %q", key, others)}
+					if d.errorOnAmbiguous {
+						errs = append(errs, amb)
+						failed = append(failed, f.Name)
+						continue
+					}
+					d.warn(Warning{Field: f.Name, Key: key, Message: amb.Error() + ": using " + key})
+				}
+
+				v, _ = lookupKey(from, key)
 			}
 		}
 
-		// look for field name in map keys
-		v, err := findVal(f.Name, from, formats)
-		if err != nil {
-			continue
+		if hint, ok := tag.option("deprecated"); ok && !defaulted &&
+			(len(tag.aliases) == 0 || containsString(tag.aliases, key)) {
+			// fields with aliases are deprecated under those only
+			d.warn(Warning{Field: f.Name, Key: key, Deprecated: hint,
+				Message: fmt.Sprintf("field %s: key %q is deprecated: %s", f.Name, key, hint)})
 		}
 
 		if v == nil {
-			// nil value in map - leave the field alone
+			// nil value in map - use the default or leave the field alone,
+			// bar sql.Null* and sql.Scanner fields, which take it as NULL
+			if def, ok := tag.option("default"); ok {
+				v, defaulted = def, true
+			} else if !acceptsNull(f.Type) {
+				continue
+			}
+		}
+
+		// get pointer to value, or for unexported fields, any setter method
+		vf, _ := fieldByIndex(vt, fl.index, true)
+		var setter reflect.Value
+		if !f.IsExported() {
+			setter = setterMethod(vt, fl)
+		}
+		if !vf.CanSet() && !setter.IsValid() {
+			errs = append(errs, &FieldError{Field: f.Name, Path: f.Name, Type: f.Type, Err: ErrCannotSet})
+			failed = append(failed, f.Name)
 			continue
 		}
 
+		// coerce into a scratch copy so that failures leave the field
+		// unchanged:
+		var err error
 		vv := reflect.ValueOf(v)
-		err = unmarshall(vf, vv)
+		if v == nil {
+			vv = reflect.Zero(interfaceType)
+		}
+		tmp := reflect.New(f.Type).Elem()
+		if vf.CanInterface() {
+			tmp.Set(vf)
+		}
+		_, raw := tag.option("raw")
+		hooked := vv
+		if !raw && len(d.hooks) > 0 {
+			// decode hooks see the value once, ahead of any tag handling
+			hooked, err = d.applyHooks(tmp.Type(), vv)
+		}
+		if raw {
+			// keep the original value untouched
+			err = assignRaw(tmp, vv, f.Name)
+		} else if err != nil || !hooked.IsValid() {
+			// a hook failed, or returned nil to leave the field alone
+		} else if how, ok := tag.option("reduce"); ok && hooked.Kind() == reflect.Slice && tmp.Kind() != reflect.Slice {
+			// combine multiple values into one
+			sep, _ := tag.option("sep")
+			err = d.reduce(tmp, hooked, how, sep)
+		} else if _, glob := tag.option("glob"); glob {
+			// expand file name patterns
+			err = d.expandGlobs(tmp, hooked)
+		} else if layout, ok := tag.option("layout"); ok && layout != "" && isTime(tmp.Type()) {
+			// parse with the field's own layout
+			err = d.unmarshallLayout(tmp, hooked, layout)
+		} else if enc, ok := tag.option("encoding"); ok && hooked.Kind() == reflect.String && isByteArray(tmp.Type()) {
+			// decode binary from text
+			err = unmarshallEncoded(tmp, hooked.String(), enc)
+		} else if sep, kvsep, ok := pairSeparators(tag); ok && tmp.Kind() == reflect.Map && (hooked.Kind() == reflect.String || isList(hooked.Type())) {
+			// key=value pairs with the field's own separators
+			err = d.unmarshallPairs(tmp, hooked, sep, kvsep)
+		} else if sep, ok := tag.option("sep"); ok && sep != "" && hooked.Kind() == reflect.String && (isList(tmp.Type()) || tmp.Kind() == reflect.Array) {
+			// split a list with its own separator
+			err = d.unmarshallSplit(tmp, hooked.String(), sep)
+		} else {
+			err = d.unmarshallValue(tmp, hooked)
+		}
+		if err == nil && setter.IsValid() {
+			err = callSetter(setter, tmp)
+		}
 
 		if err != nil {
-			errstr += err.Error() + "\n"
+			fe := &FieldError{Field: f.Name, Path: f.Name, Type: f.Type, Key: key, Value: v, Err: err, from: vv.Type()}
+			if _, secret := tag.option("secret"); secret {
+				// the cause may quote the value too
+				fe.Value, fe.Err, fe.redacted = nil, ErrInvalidValue, true
+			} else if d.hasSecrets(f.Type) {
+				fe.Value, fe.redacted = nil, true
+			}
+			locate(fe.Err, fe.Path)
+			errs = append(errs, fe)
+			failed = append(failed, f.Name)
+			continue
+		}
+		if !setter.IsValid() {
+			vf.Set(tmp)
+		}
+		assigned = append(assigned, f.Name)
+		if defaulted && md != nil {
+			md.DefaultedFields = append(md.DefaultedFields, f.Name)
+		}
+
+	}
+
+	if d.errorOnUnused || md != nil || remain != nil {
+		var unused []string
+		for k := range from {
+			if !containsString(used, k) {
+				unused = append(unused, k)
+			}
+		}
+		sort.Strings(unused)
+		if remain != nil {
+			if err := d.decodeRemain(vt, remain, from, unused); err != nil {
+				errs = append(errs, err)
+				failed = append(failed, remain.Name)
+			} else {
+				assigned = append(assigned, remain.Name)
+				unused = nil
+			}
+		}
+		if md != nil {
+			for _, name := range assigned {
+				if !containsString(md.DefaultedFields, name) {
+					md.SetFields = append(md.SetFields, name)
+				}
+			}
+			md.UnusedKeys = unused
+		}
+		if len(unused) > 0 && d.errorOnUnused {
+			errs = append(errs, d.unusedKeysError(vt.Type(), unused, formats))
+		}
+	}
+
+	if len(errs) > 0 {
+		// copy out of the pooled storage
+		return &DecodeError{
+			Assigned: append([]string(nil), assigned...),
+			Failed:   append([]string(nil), failed...),
+			Errors:   append([]error(nil), errs...),
 		}
+	}
+	return nil
+}
+
+// maxRender is the length beyond which render truncates values
+const maxRender = 64
 
+// render formats v for error messages, truncated to maxRender bytes
+func render(v interface{}) string {
+	s := fmt.Sprintf("%#v", v)
+	if len(s) > maxRender {
+		n := maxRender - 3
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n] + "..."
 	}
+	return s
+}
 
-	if errstr != "" {
-		return fmt.Errorf("%s", errstr[:len(errstr)-1]) // strips trailling newline
+// assignRaw stores vfrom in vto without conversion
+func assignRaw(vto reflect.Value, vfrom reflect.Value, name string) error {
+	if !vfrom.Type().AssignableTo(vto.Type()) {
+		return errorf(ErrUnsupportedConversion, "raw field %s: can't assign %v to %v", name, vfrom.Type(), vto.Type())
 	}
+	vto.Set(vfrom)
 	return nil
 }
 
 // Var attempts to cast the content of 'from' into the variable pointed to by 'pto'
 func Var(pto interface{}, from interface{}) error {
+	return defaultDecoder.Var(pto, from)
+}
 
-	return unmarshall(reflect.Indirect(reflect.ValueOf(pto)), reflect.ValueOf(from))
+// unmarshallString parses string s to in vto, marking failures as
+// ErrInvalidValue where they are of no other kind
+func (d *Decoder) unmarshallString(vto reflect.Value, tto reflect.Type, s string) error {
+	return invalid(d.parseString(vto, tto, s))
 }
 
-// unmarshallString parses string s to in vto
-func unmarshallString(vto reflect.Value, tto reflect.Type, s string) error {
+// parseString does the work for unmarshallString
+func (d *Decoder) parseString(vto reflect.Value, tto reflect.Type, s string) error {
 
 	// custom handlers for non-builtin types:
-	switch tto.String() {
-
-	case "time.Duration":
-		d, e := time.ParseDuration(s)
-		if e != nil {
-			return e
+	if parse, ok := stringParsers[tto]; ok {
+		v, err := parse(d, s)
+		if err != nil {
+			return err
 		}
-		vto.Set(reflect.ValueOf(d))
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Slice && !isBytes(rv.Type()) {
+			// eg a list of networks
+			if err := d.limits.checkSliceLen(rv.Len()); err != nil {
+				return err
+			}
+		}
+		vto.Set(rv)
 		return nil
 	}
 
+	// let types which implement encoding.TextUnmarshaler parse themselves:
+	if u, ok := textUnmarshaler(vto); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
 	// handle builtin types:
 	switch vto.Kind() {
 
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	case reflect.Map:
+
+		return d.unmarshallPairs(vto, reflect.ValueOf(s), "", "")
 
-		ival, err := strconv.ParseInt(s, 10, tto.Bits())
+	case reflect.Bool:
 
+		b, err := d.parseBool(s)
 		if err != nil {
-			// try again looking for B/K/M/G/T
-			ival, err = getBytes(s, err)
+			return err
+		}
+		vto.SetBool(b)
+		return nil
+
+	case reflect.Array:
+
+		if isUUIDType(tto) {
+			u, err := parseUUID(s)
 			if err != nil {
 				return err
 			}
+			vto.Set(reflect.ValueOf(u).Convert(tto))
+			return nil
 		}
+		return d.unmarshallSplit(vto, s, d.separator)
 
-		vto.SetInt(ival)
-		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+
+		// parse as int64 and leave range checks to setInt:
+		ival, err := strconv.ParseInt(s, 10, 64)
+		if err != nil && hasBasePrefix(s) {
+			// hex, octal or binary literal
+			ival, err = strconv.ParseInt(s, 0, 64)
+		}
+
+		if err != nil && !(d.saturate && isRangeErr(err)) {
+			// try again looking for B/K/M/G/T
+			ival, err = d.getBytes(s, err)
+			if err != nil {
+				return parseError(err)
+			}
+		}
+
+		return d.setInt(vto, ival)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 
-		uval, err := strconv.ParseUint(s, 10, tto.Bits())
+		uval, err := strconv.ParseUint(s, 10, 64)
+		if err != nil && hasBasePrefix(s) {
+			uval, err = strconv.ParseUint(s, 0, 64)
+		}
 
-		if err != nil {
+		if err != nil && !(d.saturate && isRangeErr(err)) {
 
-			// try again looking for B/K/M/G/T
-			ival, e := getBytes(s, err)
+			// try again looking for B/K/M/G/T, or a negative number
+			ival, e := d.getBytes(s, err)
 			if e != nil {
-				return e
+				if ival, e = strconv.ParseInt(s, 10, 64); e != nil || ival >= 0 {
+					return parseError(err)
+				}
+			}
+			if ival < 0 {
+				return d.unmarshallNegative(vto, tto, ival)
 			}
 			uval = uint64(ival)
 
 		}
 
-		vto.SetUint(uval)
-		return nil
+		return d.setUint(vto, uval)
+
+	case reflect.Slice:
+
+		if isBytes(tto) {
+			vto.SetBytes([]byte(s))
+			return nil
+		}
+		return d.unmarshallSplit(vto, s, d.separator)
 
 	case reflect.Float32, reflect.Float64:
 
 		fval, err := strconv.ParseFloat(s, tto.Bits())
+		if err != nil {
+			if p := strings.TrimSpace(s); strings.HasSuffix(p, "%") {
+				// percentage, as a fraction unless KeepPercent is set
+				if pval, perr := strconv.ParseFloat(strings.TrimSpace(p[:len(p)-1]), 64); perr == nil {
+					if !d.keepPercent {
+						pval /= 100
+					}
+					return d.unmarshallFloat(vto, tto, pval)
+				}
+			}
+		}
 
 		if err != nil {
-			return err
+			if !(d.saturate && isRangeErr(err)) {
+				return parseError(err)
+			}
+			fval = math.Copysign(floatMax(tto), fval)
 		}
 
-		vto.SetFloat(fval)
-		return nil
+		return d.unmarshallFloat(vto, tto, fval)
 	}
 
-	return fmt.Errorf("don't know how to unmarshall string to %v\n", tto)
+	return errorf(ErrUnsupportedConversion, "don't know how to unmarshall string to %v", tto)
+}
+
+// parseBool parses s as per strconv.ParseBool, also accepting yes/no and
+// on/off in any case, or accepts only the exact strings "true" and "false"
+// if the Decoder has StrictBool set
+func (d *Decoder) parseBool(s string) (bool, error) {
+	if d.strictBool {
+		switch s {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return false, errorf(ErrInvalidValue, "invalid boolean %q: expected \"true\" or \"false\"", s)
+	}
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(s))
+	if err != nil {
+		return false, errorf(ErrInvalidValue, "invalid boolean %q", s)
+	}
+	return b, nil
+}
+
+// isNumber reports whether k is an integer or floating point kind
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// isFloat reports whether k is a floating point kind
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+// isList reports whether t is a slice type other than a byte slice
+func isList(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && !isBytes(t)
+}
+
+// isBytes reports whether t is a byte slice type
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
 }
 
 // unmarshallFloat marshalls a float value into vto
-func unmarshallFloat(vto reflect.Value, tto reflect.Type, f float64) error {
+func (d *Decoder) unmarshallFloat(vto reflect.Value, tto reflect.Type, f float64) error {
 
 	switch tto.Kind() {
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		vto.SetInt(int64(f))
-		return nil
+		return d.setIntFromFloat(vto, d.round(f))
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		vto.SetUint(uint64(f))
+		f = d.round(f)
+		if f < 0 {
+			return d.unmarshallNegative(vto, tto, f)
+		}
+		return d.setUintFromFloat(vto, f)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := d.finite(f)
+		if err != nil {
+			return err
+		}
+		return d.setFloat(vto, f)
+	}
+
+	return errorf(ErrUnsupportedConversion, "don't know how to unmarshall float to %v", tto)
+}
+
+// unmarshallNegative handles negative value v destined for unsigned vto:
+// an error unless the Decoder has ClampNegativeUnsigned or Saturate set, in
+// which case vto is set to zero.
+func (d *Decoder) unmarshallNegative(vto reflect.Value, tto reflect.Type, v interface{}) error {
+	if !d.clampNegative && !d.saturate {
+		return errorf(ErrOverflow, "can't store negative value %v in %v", v, tto)
+	}
+	vto.SetUint(0)
+	return nil
+}
+
+// setterMethod returns the method of the struct holding field fl (within
+// vt) which sets it, eg SetName for field name, if there is one taking a
+// value of the field's type and returning nothing or an error
+func setterMethod(vt reflect.Value, fl field) reflect.Value {
+	parent, ok := fieldByIndex(vt, fl.index[:len(fl.index)-1], false)
+	if ok && parent.Kind() == reflect.Ptr {
+		parent = parent.Elem()
+	}
+	if !ok || !parent.IsValid() || !parent.CanAddr() || !parent.CanInterface() ||
+		!hasSetter(parent.Type(), fl.StructField) {
+		return reflect.Value{}
+	}
+	return parent.Addr().MethodByName(setterName(fl.Name))
+}
+
+// setterName returns the name of the setter method for field name, eg
+// SetName for name
+func setterName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return "Set" + string(unicode.ToUpper(r)) + name[size:]
+}
+
+// hasSetter reports whether *t has a setter method for its field f, taking
+// a value of the field's type and returning nothing or an error
+func hasSetter(t reflect.Type, f reflect.StructField) bool {
+	m, ok := reflect.PointerTo(t).MethodByName(setterName(f.Name))
+	if !ok {
+		return false
+	}
+	mt := m.Type // with the receiver as the first argument
+	return mt.NumIn() == 2 && f.Type.AssignableTo(mt.In(1)) &&
+		(mt.NumOut() == 0 || mt.NumOut() == 1 && mt.Out(0) == errorType)
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// callSetter calls setter method m with v, returning any error it returns
+func callSetter(m reflect.Value, v reflect.Value) error {
+	out := m.Call([]reflect.Value{v})
+	if len(out) == 1 && !out[0].IsNil() {
+		return out[0].Interface().(error)
+	}
+	return nil
+}
+
+// aliasKey returns the first of the keys named by `alias=` tag options
+// present in 'from', and its value
+func aliasKey(from map[string]interface{}, aliases []string) (string, interface{}, bool) {
+	for _, a := range aliases {
+		if v, ok := lookupKey(from, a); ok {
+			return a, v, true
+		}
+	}
+	return "", nil, false
+}
+
+// decodeRemain sets the `remain` field fl of struct vt to a map of the
+// unused keys of 'from' and their values
+func (d *Decoder) decodeRemain(vt reflect.Value, fl *field, from map[string]interface{}, unused []string) error {
+	rest := make(map[string]interface{}, len(unused))
+	for _, k := range unused {
+		rest[k] = from[k]
+	}
+	vf, _ := fieldByIndex(vt, fl.index, true)
+	if !vf.CanSet() {
+		return &FieldError{Field: fl.Name, Path: fl.Name, Type: fl.Type, Err: ErrCannotSet}
+	}
+	tmp := reflect.New(vf.Type()).Elem()
+	if err := d.unmarshall(tmp, reflect.ValueOf(rest)); err != nil {
+		fe := &FieldError{Field: fl.Name, Path: fl.Name, Type: fl.Type, Value: rest, Err: err, from: reflect.TypeOf(rest)}
+		locate(fe.Err, fe.Path)
+		return fe
+	}
+	vf.Set(tmp)
+	return nil
+}
+
+// unmarshallMap coerces each key and value of map vfrom into a new map
+// of vto's type
+func (d *Decoder) unmarshallMap(vto reflect.Value, vfrom reflect.Value) error {
+	tto := vto.Type()
+	if vfrom.IsNil() {
+		// keep nil distinct from empty
+		vto.Set(reflect.Zero(tto))
 		return nil
 	}
+	m := reflect.MakeMapWithSize(tto, vfrom.Len())
+	for _, kfrom := range sortedKeys(vfrom) {
+		k := reflect.New(tto.Key()).Elem()
+		if err := d.unmarshall(k, kfrom); err != nil {
+			return err
+		}
+		v := reflect.New(tto.Elem()).Elem()
+		if err := d.unmarshall(v, vfrom.MapIndex(kfrom)); err != nil {
+			return &elemError{fmt.Sprintf("[%v]", kfrom), fmt.Sprintf("map key %v", kfrom), err}
+		}
+		m.SetMapIndex(k, v)
+	}
+	vto.Set(m)
+	return nil
+}
+
+// stringMap returns v as a map[string]interface{} if it is a map with
+// string or interface{} keys, such as the nested objects of decoded JSON or
+// YAML
+func stringMap(v reflect.Value) (map[string]interface{}, bool) {
+	if v.Kind() != reflect.Map {
+		return nil, false
+	}
+	switch v.Type().Key().Kind() {
+	case reflect.String:
+		if m, ok := v.Interface().(map[string]interface{}); ok {
+			return m, true
+		}
+	case reflect.Interface:
+		// eg from gopkg.in/yaml.v2; keys are printed as per fmt
+	default:
+		return nil, false
+	}
+	m := make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		m[mapKeyString(iter.Key())] = iter.Value().Interface()
+	}
+	return m, true
+}
+
+// mapKeyString returns map key k as a string
+func mapKeyString(k reflect.Value) string {
+	if s, ok := k.Interface().(string); ok {
+		return s
+	}
+	return fmt.Sprint(k.Interface())
+}
 
-	return fmt.Errorf("don't know how to unmarshall float to %v\n", tto)
+// sortedKeys returns the keys of map v ordered by their printed form, so
+// that maps are processed (and errors reported) deterministically
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	return keys
 }
 
 // unmarshall tries to parse vfrom value into vto
-func unmarshall(vto reflect.Value, vfrom reflect.Value) error {
+func (d *Decoder) unmarshall(vto reflect.Value, vfrom reflect.Value) error {
+	if len(d.hooks) > 0 {
+		var err error
+		if vfrom, err = d.applyHooks(vto.Type(), vfrom); err != nil || !vfrom.IsValid() {
+			return err
+		}
+	}
+	return d.unmarshallValue(vto, vfrom)
+}
 
-	// try for direct assign:
+// unmarshallValue is unmarshall once any decode hooks have been applied
+func (d *Decoder) unmarshallValue(vto reflect.Value, vfrom reflect.Value) error {
+
+	// unwrap interface{} values, eg the elements of a []interface{}:
+	if vfrom.Kind() == reflect.Interface {
+		if vfrom.IsNil() {
+			// nil element - leave the target alone, bar SQL NULLs
+			return setNull(vto)
+		}
+		vfrom = vfrom.Elem()
+	}
+
+	// discriminated unions choose their concrete type from the map:
+	if u, ok := d.lookupUnion(vto.Type()); ok {
+		if m, isMap := stringMap(vfrom); isMap {
+			return d.unmarshallUnion(vto, u, m)
+		}
+	}
+
+	// float to float goes via unmarshallFloat to apply the NaN/Inf policy:
 	tto := vto.Type()
+	if isFloat(tto.Kind()) && isFloat(vfrom.Kind()) {
+		return d.unmarshallFloat(vto, tto, vfrom.Float())
+	}
+
+	// try for direct assign:
 	if vfrom.Type().AssignableTo(tto) {
+		if d.copyOnAssign {
+			vfrom = copyValue(vfrom)
+		}
 		vto.Set(vfrom)
 		return nil
 	}
 
+	// registered converters come next:
+	if c, ok := d.lookupConverter(tto); ok {
+		return convert(c, vto, vfrom)
+	}
+
+	// dereference pointer sources (bar those which can print themselves
+	// into a string), treating nil like a nil map value:
+	if vfrom.Kind() == reflect.Ptr {
+		if vfrom.IsNil() {
+			return setNull(vto)
+		}
+		if _, ok := vfrom.Interface().(fmt.Stringer); !ok || tto.Kind() != reflect.String {
+			return d.unmarshallValue(vto, vfrom.Elem())
+		}
+	}
+
+	// pointer targets are allocated as needed and the value coerced into a
+	// copy of the pointee, so that optional fields can be modelled:
+	if tto.Kind() == reflect.Ptr {
+		elem := reflect.New(tto.Elem())
+		if !vto.IsNil() {
+			elem.Elem().Set(vto.Elem())
+		}
+		if err := d.unmarshallValue(elem.Elem(), vfrom); err != nil {
+			return err
+		}
+		vto.Set(elem)
+		return nil
+	}
+
+	// sql.Null* targets take the value leniently, and other sql.Scanners
+	// scan it themselves:
+	if isSQLNull(tto) {
+		return d.unmarshallNull(vto, vfrom)
+	}
+	if s, ok := scanner(vto); ok {
+		return s.Scan(vfrom.Interface())
+	}
+
+	// JSON payloads destined for composite types are decoded and recursed:
+	if decoded, ok := decodeJSON(vfrom, tto); ok {
+		if err := d.checkLimits(decoded); err != nil {
+			return err
+		}
+		if m, isMap := decoded.(map[string]interface{}); isMap && tto.Kind() == reflect.Struct {
+			return d.unmarshallStruct(vto, m, nil)
+		}
+		if decoded == nil {
+			// JSON null - leave the target alone
+			return nil
+		}
+		return d.unmarshall(vto, reflect.ValueOf(decoded))
+	}
+
+	// nested maps fill nested structs field-by-field:
+	if m, ok := stringMap(vfrom); ok && tto.Kind() == reflect.Struct {
+		return d.unmarshallStruct(vto, m, nil)
+	}
+
+	// unmarshall maps key-by-key:
+	if vfrom.Kind() == reflect.Map && tto.Kind() == reflect.Map {
+		return d.unmarshallMap(vto, vfrom)
+	}
+
+	// json.Number sources for numeric targets are the number they hold:
+	if vfrom.Type() == jsonNumberType && (isNumber(tto.Kind()) || tto == timeType) {
+		if n, ok := numberValue(vfrom.String()); ok {
+			return d.unmarshallValue(vto, n)
+		}
+	}
+
+	// text for types which parse themselves (including string types, which
+	// would otherwise be set directly) goes via unmarshallString:
+	if text, ok := textSource(vfrom); ok {
+		if _, ok := textUnmarshaler(vto); ok {
+			return d.unmarshallString(vto, tto, text)
+		}
+	}
+
+	// raw bytes become strings directly rather than via fmt ("[104 105]"),
+	// unless the type knows how to print itself (eg net.IP):
+	if tto.Kind() == reflect.String && isBytes(vfrom.Type()) {
+		if _, ok := vfrom.Interface().(fmt.Stringer); !ok {
+			vto.SetString(string(vfrom.Bytes()))
+			return nil
+		}
+	}
+
 	// unmarshall from slices recursively:
-	if vfrom.Kind() == reflect.Slice {
-		if vto.Kind() == reflect.Slice {
+	if vfrom.Kind() == reflect.Slice || vfrom.Kind() == reflect.Array {
+		if tto == ipSliceType {
+			// IP lists may contain lists or ranges which expand to several elements
+			return d.unmarshallIPs(vto, vfrom)
+		}
+		if vto.Kind() == reflect.Array {
+			// ...to an array of the same length:
+			if vfrom.Len() != vto.Len() {
+				return errorf(ErrUnsupportedConversion, "can't coerce %d values into %v", vfrom.Len(), tto)
+			}
+			for j := 0; j < vfrom.Len(); j++ {
+				if err := d.unmarshall(vto.Index(j), vfrom.Index(j)); err != nil {
+					return &elemError{fmt.Sprintf("[%d]", j), fmt.Sprintf("element %d", j), err}
+				}
+			}
+			return nil
+
+		} else if vto.Kind() == reflect.Slice {
 			// ...to a slice:
+			if vfrom.Kind() == reflect.Slice && vfrom.IsNil() {
+				// keep nil distinct from empty
+				vto.Set(reflect.Zero(tto))
+				return nil
+			}
 			// set slice size:
 			vto.Set(reflect.MakeSlice(vto.Type(), vfrom.Len(), vfrom.Len()))
 
 			for j := 0; j < vfrom.Len(); j++ {
 				// unmarshall slice elements
-				err := unmarshall(vto.Index(j), vfrom.Index(j))
+				err := d.unmarshall(vto.Index(j), vfrom.Index(j))
 				if err != nil {
-					return err
+					return &elemError{fmt.Sprintf("[%d]", j), fmt.Sprintf("element %d", j), err}
 				}
 			}
 			return nil
 
+		} else if vto.Kind() == reflect.Map {
+			// ...to a map, from "key=value" strings:
+			return d.unmarshallPairs(vto, vfrom, "", "")
+
+		} else if n, ok := countTrue(vfrom); ok && isNumber(tto.Kind()) {
+			// a repeated flag, eg [true true] from docopt for "-v -v"
+			return d.unmarshallInt(vto, tto, n)
 		} else if vfrom.Len() == 1 {
 			// tolerate mapping of slices with length==1 to a single field
-			return unmarshall(vto, vfrom.Index(0))
+			return d.unmarshall(vto, vfrom.Index(0))
 		} else {
-			return fmt.Errorf("can't coerce %v from multi-value slice", tto)
+			return errorf(ErrUnsupportedConversion, "can't coerce %v from multi-value slice", tto)
 		}
 	}
 
@@ -246,19 +1088,64 @@ func unmarshall(vto reflect.Value, vfrom reflect.Value) error {
 		return nil
 	}
 
+	// numbers destined for time.Time are Unix timestamps:
+	if tto == timeType && isNumber(vfrom.Kind()) {
+		return d.unmarshallEpoch(vto, vfrom, d.epochUnit)
+	}
+
 	// case-by-case for everything else:
 	switch vfrom.Kind() {
 
 	case reflect.String:
-		return unmarshallString(vto, tto, vfrom.String())
+		err := d.unmarshallString(vto, tto, vfrom.String())
+		if err != nil {
+			// the type may know better
+			if ok, jerr := unmarshallJSON(vto, vfrom.String()); ok {
+				return jerr
+			}
+		}
+		return err
 
 	case reflect.Float32, reflect.Float64:
-		return unmarshallFloat(vto, tto, vfrom.Float())
+		return d.unmarshallFloat(vto, tto, vfrom.Float())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return d.unmarshallInt(vto, tto, vfrom.Int())
 
-		// case Int, Uint etc should generally be handled by AssignableTo or fmt.Sprintf
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return d.unmarshallUint(vto, tto, vfrom.Uint())
+
+	case reflect.Bool:
+		if isNumber(tto.Kind()) {
+			// a flag given once, counting as 1
+			n := int64(0)
+			if vfrom.Bool() {
+				n = 1
+			}
+			return d.unmarshallInt(vto, tto, n)
+		}
 	}
 
-	return fmt.Errorf("Don't know how to unmarshall %v to %v\n", vfrom.Type(), tto)
+	return errorf(ErrUnsupportedConversion, "don't know how to unmarshall %v to %v", vfrom.Type(), tto)
+}
+
+// unmarshallSplit splits s on sep and coerces the trimmed elements into
+// slice or array vto
+func (d *Decoder) unmarshallSplit(vto reflect.Value, s string, sep string) error {
+	parts := []string{}
+	if strings.TrimSpace(s) != "" {
+		if sep == "" {
+			sep = ","
+		}
+		if err := d.limits.checkSliceLen(strings.Count(s, sep) + 1); err != nil {
+			return err
+		}
+		parts = strings.Split(s, sep)
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+	}
+	return d.unmarshall(vto, reflect.ValueOf(parts))
 }
 
 // Int tries to return an int value based on content of 'from'
@@ -303,93 +1190,279 @@ func String(from interface{}) (s string) {
 	return
 }
 
-// findVal tries to find map key matching field name formatted as per formats
-func findVal(baseName string, from map[string]interface{}, formats []string) (interface{}, error) {
+// findVal tries to find the map key matching field name formatted as per
+// formats.  Formats are tried in the order given and, for each, the name
+// variants in the order given by nameVariants; the first key found wins.
+// Any different keys which also match are returned in 'others', in order
+// of precedence; 'others' is only valid until sc is released.
+func (sc *scratch) findVal(baseName string, from map[string]interface{}, formats []string) (key string, others []string, err error) {
 
 	if len(formats) == 0 {
 		// handle case where no formats supplied
-		formats = []string{"%s"}
+		formats = defaultFormats
+	}
+
+	found := sc.found[:0]
+	for _, pat := range formats {
+		for _, name := range nameVariants(baseName) {
+			sc.buf = appendKey(sc.buf[:0], pat, name)
+			if _, ok := from[string(sc.buf)]; ok && !containsBytes(found, sc.buf) {
+				found = append(found, string(sc.buf))
+			} else if !ok && isPointer(pat) {
+				if _, ok := lookupKey(from, string(sc.buf)); ok {
+					found = append(found, string(sc.buf))
+				}
+			}
+		}
+	}
+	sc.found = found
+
+	if len(found) == 0 {
+		return "", nil, notFoundError{baseName: baseName, formats: formats}
+	}
+
+	return found[0], found[1:], nil
+}
+
+// findKey is findVal for a precomputed list of candidate keys, as given
+// by candidateKeys
+func (sc *scratch) findKey(from map[string]interface{}, candidates []string) (key string, others []string, err error) {
+	found := sc.found[:0]
+	for _, k := range candidates {
+		if _, ok := lookupKey(from, k); ok {
+			found = append(found, k)
+		}
 	}
+	sc.found = found
 
-	var result interface{}
-	var ok bool
-	tried := "" // accumulates patterns tried, for possible error reporting
+	if len(found) == 0 {
+		return "", nil, notFoundError{keys: candidates}
+	}
+	return found[0], found[1:], nil
+}
 
-Found:
-	for _, name := range nameVariants(baseName) {
-		for _, pat := range formats {
-			key := fmt.Sprintf(pat, name)
-			result, ok = from[key]
-			if ok {
-				break Found
+// candidateKeys lists the distinct keys findVal tries for field name
+// baseName, in order of precedence
+func candidateKeys(baseName string, formats []string) []string {
+	if len(formats) == 0 {
+		formats = defaultFormats
+	}
+	var keys []string
+	for _, pat := range formats {
+		for _, name := range nameVariants(baseName) {
+			if k := string(appendKey(nil, pat, name)); !containsString(keys, k) {
+				keys = append(keys, k)
 			}
-			tried += key + "|"
 		}
 	}
+	return keys
+}
 
-	if !ok {
-		return nil, fmt.Errorf("[%s] not found in map", tried[:len(tried)-1])
+var defaultFormats = []string{"%s"}
+
+// foldKey returns k in lower case without hyphens or underscores, so that
+// eg "MaxRetries", "max_retries", "max-retries" and "MAX_RETRIES" are equal
+func foldKey(k string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, k)
+}
+
+// foldIndex maps the folded keys of 'from' to the keys they came from, in
+// sorted order
+func foldIndex(from map[string]interface{}) map[string][]string {
+	keys := sortedStrings(from)
+	folded := make(map[string][]string, len(keys))
+	for _, k := range keys {
+		fk := foldKey(k)
+		folded[fk] = append(folded[fk], k)
 	}
+	return folded
+}
 
-	return result, nil
+// sortedStrings returns the keys of 'from' in sorted order
+func sortedStrings(from map[string]interface{}) []string {
+	keys := make([]string, 0, len(from))
+	for k := range from {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// matchedKey is findVal for MatchKeys, returning the keys (in sorted
+// order) for which match reports true, or notFound if there are none
+func matchedKey(baseName string, keys []string, match func(field, key string) bool, notFound error) (key string, others []string, err error) {
+	var found []string
+	for _, k := range keys {
+		if match(baseName, k) {
+			found = append(found, k)
+		}
+	}
+	if len(found) == 0 {
+		return "", nil, notFound
+	}
+	return found[0], found[1:], nil
+}
+
+// foldedKey is findVal for FoldKeys, matching field name baseName
+// formatted as per formats against the folded keys; it returns notFound
+// if there is no match
+func foldedKey(baseName string, folded map[string][]string, formats []string, notFound error) (key string, others []string, err error) {
+	if len(formats) == 0 {
+		formats = defaultFormats
+	}
+	var found []string
+	for _, pat := range formats {
+		for _, k := range folded[foldKey(fmt.Sprintf(pat, baseName))] {
+			if !containsString(found, k) {
+				found = append(found, k)
+			}
+		}
+	}
+	if len(found) == 0 {
+		return "", nil, notFound
+	}
+	return found[0], found[1:], nil
+}
+
+// notFoundError reports the keys tried for a field, either as listed or as
+// derived from its name and formats; its message is only built on demand
+// since missing keys are usually ignored; see ErrorOnMissingKeys
+type notFoundError struct {
+	baseName string
+	formats  []string
+	keys     []string
+}
+
+func (e notFoundError) Error() string {
+	tried := e.keys
+	for _, pat := range e.formats {
+		for _, name := range nameVariants(e.baseName) {
+			tried = append(tried, fmt.Sprintf(pat, name))
+		}
+	}
+	return fmt.Sprintf("[%s] not found in map", strings.Join(tried, "|"))
+}
+
+// Is reports whether target is ErrKeyNotFound
+func (e notFoundError) Is(target error) bool {
+	return target == ErrKeyNotFound
 }
 
 var uppersRE = regexp.MustCompile(`[[:upper:]]`)
 
+// variantCache holds the results of nameVariants, which only ever sees
+// the field names of the struct types being decoded
+var variantCache sync.Map
+
+// nameVariants returns the distinct forms of field name base which are
+// matched against map keys, in order of precedence: as-is, lowercase,
+// hyphenated, underscored, lowercase hyphenated and lowercase underscored.
+// The result is shared and must not be modified.
 func nameVariants(base string) []string {
-	hyphens := strings.TrimLeft(uppersRE.ReplaceAllStringFunc(base, func(ch string) string {
-		return "-" + ch
-	}), "-")
-	unders := strings.TrimLeft(uppersRE.ReplaceAllStringFunc(base, func(ch string) string {
-		return "_" + ch
-	}), "_")
-	allM := map[string]struct{}{
-		base: struct{}{},
-		strings.ToLower(base): struct{}{},
-		hyphens:               struct{}{},
-		unders:                struct{}{},
-		strings.ToLower(hyphens): struct{}{},
-		strings.ToLower(unders):  struct{}{},
+	if all, ok := variantCache.Load(base); ok {
+		return all.([]string)
 	}
+
+	hyphens := wordBreaks(base, "-")
+	unders := wordBreaks(base, "_")
 	var all []string
-	for n := range allM {
-		all = append(all, n)
+	for _, n := range []string{
+		base,
+		strings.ToLower(base),
+		hyphens,
+		unders,
+		strings.ToLower(hyphens),
+		strings.ToLower(unders),
+	} {
+		if !containsString(all, n) {
+			all = append(all, n)
+		}
 	}
+	variantCache.Store(base, all)
 	return all
 }
 
-// getBytes parses strings of the format '1.2G' and interprets a kB, MB,
-// GB etc.
-func getBytes(s string, err error) (int64, error) {
-	const (
-		b = 1
-		k = b << 10
-		m = k << 10
-		g = m << 10
-		t = g << 10
-	)
-	var mult int64
-	switch strings.ToUpper(string(s[len(s)-1])) {
-	case "B":
-		mult = b
-	case "K":
-		mult = k
-	case "M":
-		mult = m
-	case "G":
-		mult = g
-	case "T":
-		mult = t
-	default:
+// wordBreaks inserts sep before each upper case letter of base, bar a
+// leading one, eg "MapDown" -> "Map-Down"
+func wordBreaks(base string, sep string) string {
+	return strings.TrimLeft(uppersRE.ReplaceAllStringFunc(base, func(ch string) string {
+		return sep + ch
+	}), sep)
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// byteUnits maps the (upper case) size suffixes accepted by getBytes to
+// their multipliers, bar the bare K, M, G, T and P, whose base depends on
+// the SIByteUnits option
+var byteUnits = map[string]float64{
+	"B":  1,
+	"KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12, "PB": 1e15,
+	"KIB": 1 << 10, "MIB": 1 << 20, "GIB": 1 << 30, "TIB": 1 << 40, "PIB": 1 << 50,
+	"KI": 1 << 10, "MI": 1 << 20, "GI": 1 << 30, "TI": 1 << 40, "PI": 1 << 50,
+}
+
+const bareByteUnits = "KMGTP"
+
+// getBytes parses sizes such as "1.2G", "10MB" and "512KiB", returning
+// err if s has no size suffix.  Decimal (KB, MB etc.) and binary (KiB,
+// MiB etc.) suffixes have their standard meanings; bare K, M etc. are
+// binary unless the SIByteUnits option is set.
+func (d *Decoder) getBytes(s string, err error) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := len(s)
+	for i > 0 && unicode.IsLetter(rune(s[i-1])) {
+		i--
+	}
+	num, unit := strings.TrimSpace(s[:i]), strings.ToUpper(s[i:])
+	if num == "" || unit == "" {
 		return 0, err
 	}
-	ival, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
-	if err != nil {
-		fval, err := strconv.ParseFloat(s[:len(s)-1], 64)
-		if err != nil {
+
+	mult, ok := byteUnits[unit]
+	if !ok {
+		p := strings.Index(bareByteUnits, unit)
+		if len(unit) != 1 || p < 0 {
 			return 0, err
 		}
-		return int64(fval * float64(mult)), nil
+		base := 1024.0
+		if d.siBytes {
+			base = 1000
+		}
+		mult = math.Pow(base, float64(p+1))
+	}
+
+	if ival, err := strconv.ParseInt(num, 10, 64); err == nil {
+		if m := int64(mult); ival <= math.MaxInt64/m && ival >= math.MinInt64/m {
+			return ival * m, nil
+		}
+	}
+	fval, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	fval = d.round(fval * mult)
+	if fval >= math.MaxInt64 || fval < math.MinInt64 {
+		if !d.saturate {
+			return 0, overflowError(s, reflect.TypeOf(int64(0)))
+		}
+		if fval < 0 {
+			return math.MinInt64, nil
+		}
+		return math.MaxInt64, nil
 	}
-	return ival * mult, nil
+	return int64(fval), nil
 }
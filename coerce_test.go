@@ -1,10 +1,13 @@
 package coerce
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"net/mail"
+	"net/url"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,41 +26,6 @@ func report(err error, expected interface{}, got interface{}, t *testing.T) {
 	}
 }
 
-func Test_Struct(t *testing.T) {
-	log.SetFlags(log.Flags() | log.Lshortfile)
-
-	type x struct {
-		intslice foo
-		Boolval  bool
-		s        string
-		notthere int
-		MapDown  string
-	}
-
-	mymap := map[string]interface{}{
-		"--intslice": []string{"5", "-12", "0.5k"},
-		"--Boolval":  true,
-		"-s":         nil,
-		"--map-down": "now implemented",
-	}
-
-	var myx x
-	myx.s = "hello"
-
-	expected := x{
-		intslice: []int{5, -12, 512},
-		Boolval:  true,
-		s:        "hello",
-		notthere: 0,
-		MapDown:  "now implemented",
-	}
-
-	log.Printf("%#v", mymap)
-	err := Struct(&myx, mymap, "--%s", "-%s")
-
-	report(err, expected, myx, t)
-}
-
 func Test_Var_int_string(t *testing.T) {
 
 	var i int
@@ -85,11 +53,7 @@ func Test_Var_string_date_string(t *testing.T) {
 
 	var t2 time.Time
 	err = Var(&t2, s)
-	if err != nil {
-		// FIXME
-	} else {
-		report(nil, tm, t2, t)
-	}
+	report(err, tm, t2, t)
 }
 
 func Test_string_duration_string(t *testing.T) {
@@ -122,3 +86,590 @@ func Test_float(t *testing.T) {
 	f2, err := Float32(s)
 	report(err, f, f2, t)
 }
+
+func Test_mail_address(t *testing.T) {
+	type x struct {
+		From mail.Address
+		To   []mail.Address
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"from": "Alice <alice@example.com>",
+		"to":   "bob@example.com, Carol <carol@example.com>",
+	})
+
+	expected := x{
+		From: mail.Address{Name: "Alice", Address: "alice@example.com"},
+		To: []mail.Address{
+			{Address: "bob@example.com"},
+			{Name: "Carol", Address: "carol@example.com"},
+		},
+	}
+	report(err, expected, myx, t)
+
+	if err := Var(&myx.From, "not an address"); err == nil {
+		t.Errorf("expected error for invalid address")
+	}
+}
+
+func Test_url_and_mail_pointers(t *testing.T) {
+	var o struct {
+		Webhook  *url.URL
+		Base     url.URL
+		NotifyTo *mail.Address
+	}
+	err := Struct(&o, map[string]interface{}{
+		"webhook":   "https://hooks.example.com/notify?x=1",
+		"base":      " http://localhost:8080/api ",
+		"notify-to": "Ops <ops@example.com>",
+	})
+	if err != nil || o.Webhook == nil || o.NotifyTo == nil {
+		t.Fatalf("expected pointers to be set, got %+v (%v)", o, err)
+	}
+	report(err, "hooks.example.com", o.Webhook.Host, t)
+	report(err, "1", o.Webhook.Query().Get("x"), t)
+	report(err, "/api", o.Base.Path, t)
+	report(err, mail.Address{Name: "Ops", Address: "ops@example.com"}, *o.NotifyTo, t)
+
+	err = Struct(&o, map[string]interface{}{"webhook": "http://[::1", "notify-to": "nobody"})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "Webhook" {
+		t.Errorf("expected Webhook field error, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "NotifyTo") {
+		t.Errorf("expected NotifyTo field error, got %v", err)
+	}
+}
+
+func Test_Struct_raw(t *testing.T) {
+	type x struct {
+		Port    int
+		Options interface{} `coerce:",raw"`
+	}
+
+	opts := map[string]interface{}{"verbose": "yes"}
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"port":    "8080",
+		"options": opts,
+	})
+
+	report(err, x{8080, opts}, myx, t)
+}
+
+func Test_Var_bytes_string(t *testing.T) {
+	s := ""
+	err := Var(&s, []byte("hi"))
+	report(err, "hi", s, t)
+
+	var b []byte
+	err = Var(&b, "hi")
+	report(err, []byte("hi"), b, t)
+}
+
+func Test_Var_interface_slice(t *testing.T) {
+	var is []int
+	err := Var(&is, []interface{}{float64(80), "443", "1k"})
+	report(err, []int{80, 443, 1024}, is, t)
+
+	var ss []string
+	err = Var(&ss, []interface{}{"a", 1, true})
+	report(err, []string{"a", "1", "true"}, ss, t)
+}
+
+func Test_Struct_pointer_source(t *testing.T) {
+	type x struct {
+		Name  string
+		Count int
+		Ratio float64
+	}
+
+	name, count := "widget", "12"
+	var ratio *float64
+
+	myx := x{Ratio: 0.5}
+	err := Struct(&myx, map[string]interface{}{
+		"name":  &name,
+		"count": &count,
+		"ratio": ratio,
+	})
+
+	report(err, x{"widget", 12, 0.5}, myx, t)
+}
+
+func Test_Var_nil_vs_empty_slice(t *testing.T) {
+	is := []int{1}
+	err := Var(&is, []string(nil))
+	if err != nil || is != nil {
+		t.Errorf("expected nil slice, got %#v (%v)", is, err)
+	}
+
+	err = Var(&is, []string{})
+	if err != nil || is == nil || len(is) != 0 {
+		t.Errorf("expected empty non-nil slice, got %#v (%v)", is, err)
+	}
+}
+
+func Test_Struct_error_order(t *testing.T) {
+	type x struct {
+		Zeta  int
+		Alpha int
+		Mid   map[string]int
+	}
+
+	var myx x
+	for i := 0; i < 10; i++ {
+		err := Struct(&myx, map[string]interface{}{
+			"alpha": "b",
+			"zeta":  "a",
+			"mid":   map[string]interface{}{"z": "x", "a": "y"},
+		})
+		if err == nil || !strings.HasPrefix(err.Error(), `field Zeta (int) from key "zeta": can't coerce "a" (string)`) ||
+			!strings.Contains(err.Error(), `map key a:`) {
+			t.Fatalf("unexpected error order: %v", err)
+		}
+	}
+}
+
+func Test_Struct_partial(t *testing.T) {
+	type x struct {
+		Good  int
+		Bad   []int
+		Other string
+	}
+
+	myx := x{Bad: []int{7}}
+	err := Struct(&myx, map[string]interface{}{
+		"good": "1",
+		"bad":  []string{"2", "oops"},
+	})
+
+	report(nil, x{Good: 1, Bad: []int{7}}, myx, t)
+
+	de, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("expected *DecodeError, got %#v", err)
+	}
+	report(nil, []string{"Good"}, de.Assigned, t)
+	report(nil, []string{"Bad"}, de.Failed, t)
+	if len(de.Errors) != 1 {
+		t.Errorf("expected 1 error, got %v", de.Errors)
+	}
+}
+
+func Test_Struct_error_detail(t *testing.T) {
+	type x struct {
+		Count uint8
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"--count": "a very long value which goes on and on and on and on and on and on",
+	}, "--%s")
+
+	expected := `field Count (uint8) from key "--count": can't coerce "a very long value which goes on and on and on and on and on ... (string): `
+	if err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("expected error starting %s, got %v", expected, err)
+	}
+}
+
+func Test_Struct_tag_key(t *testing.T) {
+	type x struct {
+		DryRun bool `coerce:"--dry-run"`
+		Count  int  `coerce:"file.count"`
+		Name   string
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"--dry-run":    "true",
+		"file.count":   "3",
+		"--file.count": "4",
+		"--name":       "n",
+	}, "--%s")
+	report(err, x{true, 3, "n"}, myx, t)
+
+	m, err := Map(myx, "--%s")
+	report(err, map[string]interface{}{"--dry-run": true, "file.count": 3, "--Name": "n"}, m, t)
+}
+
+func Test_Struct_nested(t *testing.T) {
+	type server struct {
+		Host string
+		Port int
+	}
+	type x struct {
+		Name    string
+		Server  server
+		Backups []server
+		Labels  map[string]server
+	}
+
+	myx := x{Server: server{Host: "localhost"}}
+	err := Struct(&myx, map[string]interface{}{
+		"name":   "app",
+		"server": map[string]interface{}{"port": "8080"},
+		"backups": []interface{}{
+			map[string]interface{}{"host": "b1", "port": 1},
+			map[string]string{"host": "b2", "port": "2"},
+		},
+		"labels": map[string]interface{}{
+			"main": map[string]interface{}{"host": "m"},
+		},
+	})
+
+	expected := x{
+		Name:    "app",
+		Server:  server{"localhost", 8080},
+		Backups: []server{{"b1", 1}, {"b2", 2}},
+		Labels:  map[string]server{"main": {Host: "m"}},
+	}
+	report(err, expected, myx, t)
+
+	err = Struct(&myx, map[string]interface{}{
+		"server": map[string]interface{}{"port": "eighty"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `field Server.Port (int) from key "port"`) {
+		t.Errorf("expected nested field error, got %v", err)
+	}
+	report(nil, 8080, myx.Server.Port, t)
+}
+
+func Test_Struct_pointer_fields(t *testing.T) {
+	type inner struct {
+		A, B int
+	}
+	type x struct {
+		Count   *int
+		Name    *string
+		Missing *bool
+		Inner   *inner
+		Ptrs    []*uint8
+	}
+
+	shared := &inner{A: 1}
+	myx := x{Inner: shared}
+	err := Struct(&myx, map[string]interface{}{
+		"count": "3",
+		"name":  7,
+		"inner": map[string]interface{}{"b": "2"},
+		"ptrs":  []string{"4", "5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if myx.Count == nil || *myx.Count != 3 || myx.Name == nil || *myx.Name != "7" || myx.Missing != nil {
+		t.Errorf("unexpected pointer fields %#v", myx)
+	}
+	report(nil, inner{1, 2}, *myx.Inner, t)
+	report(nil, inner{A: 1}, *shared, t)
+	if len(myx.Ptrs) != 2 || *myx.Ptrs[1] != 5 {
+		t.Errorf("unexpected pointer slice %v", myx.Ptrs)
+	}
+}
+
+func Test_Var_bool_string(t *testing.T) {
+	for s, expected := range map[string]bool{
+		"true": true, "FALSE": false, "1": true, "0": false,
+		"yes": true, "No": false, " on ": true, "OFF": false,
+	} {
+		b := !expected
+		err := Var(&b, s)
+		if err != nil || b != expected {
+			t.Errorf("%q: expected %v, got %v (%v)", s, expected, b, err)
+		}
+	}
+
+	var b bool
+	if err := Var(&b, "maybe"); err == nil {
+		t.Errorf("expected error for invalid boolean")
+	}
+}
+
+func Test_Struct_FieldError(t *testing.T) {
+	type x struct {
+		Port  uint8
+		Hosts []string
+		Delay time.Duration
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"port":  "300",
+		"delay": "soon",
+	})
+
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("expected *FieldError, got %#v", err)
+	}
+	report(nil, "Port", fe.Field, t)
+	report(nil, "port", fe.Key, t)
+	report(nil, "300", fe.Value, t)
+
+	de := err.(*DecodeError)
+	var failed []string
+	for _, e := range de.Unwrap() {
+		failed = append(failed, e.(*FieldError).Field)
+	}
+	report(nil, []string{"Port", "Delay"}, failed, t)
+}
+
+func Test_Struct_slice_of_structs(t *testing.T) {
+	type server struct {
+		Host string
+		Port int
+	}
+	type x struct {
+		Servers []server
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"servers": []map[string]interface{}{
+			{"host": "a", "port": "1"},
+			{"host": "b", "port": 2.0},
+		},
+	})
+	report(err, x{[]server{{"a", 1}, {"b", 2}}}, myx, t)
+
+	err = Struct(&myx, map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"host": "c"},
+			map[string]interface{}{"port": "many"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `element 1: field Servers[1].Port (int) from key "port"`) {
+		t.Errorf("expected element error, got %v", err)
+	}
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "Servers" || !errors.As(fe.Err, &fe) || fe.Field != "Port" {
+		t.Errorf("expected nested *FieldError, got %#v", err)
+	}
+	report(nil, x{[]server{{"a", 1}, {"b", 2}}}, myx, t)
+}
+
+func Test_Struct_split(t *testing.T) {
+	var o struct {
+		Hosts []string
+		Ports []uint16
+		Paths []string `coerce:",sep=:"`
+		Sizes []int
+		None  []string
+		Data  []byte
+	}
+	err := Struct(&o, map[string]interface{}{
+		"hosts": "a, b,c",
+		"ports": "80,443",
+		"paths": "/bin:/usr/bin",
+		"sizes": "1k",
+		"none":  "",
+		"data":  "a,b",
+	})
+	report(err, []string{"a", "b", "c"}, o.Hosts, t)
+	report(err, []uint16{80, 443}, o.Ports, t)
+	report(err, []string{"/bin", "/usr/bin"}, o.Paths, t)
+	report(err, []int{1024}, o.Sizes, t)
+	report(err, []string{}, o.None, t)
+	report(err, []byte("a,b"), o.Data, t)
+
+	var list []string
+	err = NewDecoder(Separator(";")).Var(&list, "a,b;c")
+	report(err, []string{"a,b", "c"}, list, t)
+
+	err = Struct(&o, map[string]interface{}{"ports": "80,x"})
+	if err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Errorf("expected element error, got %v", err)
+	}
+}
+
+func Test_Struct_array(t *testing.T) {
+	var o struct {
+		Mask  [4]byte
+		Names [3]string
+		Pair  [2]float64
+	}
+	err := Struct(&o, map[string]interface{}{
+		"mask":  "255, 255, 255, 0",
+		"names": []interface{}{"a", "b", "c"},
+		"pair":  [2]string{"1.5", "2"},
+	})
+	report(err, [4]byte{255, 255, 255, 0}, o.Mask, t)
+	report(err, [3]string{"a", "b", "c"}, o.Names, t)
+	report(err, [2]float64{1.5, 2}, o.Pair, t)
+
+	err = Var(&o.Names, []string{"x", "y"})
+	report(nil, "can't coerce 2 values into [3]string", fmt.Sprint(err), t)
+	report(nil, [3]string{"a", "b", "c"}, o.Names, t)
+
+	if err := Var(&o.Mask, "1,2,3,x"); err == nil {
+		t.Error("expected element error")
+	}
+}
+
+func Test_Struct_error_paths(t *testing.T) {
+	type listener struct {
+		Port int
+	}
+	type server struct {
+		Name      string
+		Listeners []listener
+		Limits    map[string]listener
+	}
+	var o struct {
+		Server server
+	}
+	err := Struct(&o, map[string]interface{}{
+		"server": map[string]interface{}{
+			"listeners": []interface{}{
+				map[string]interface{}{"port": 1},
+				map[string]interface{}{"port": 2},
+				map[string]interface{}{"port": "x"},
+			},
+			"limits": map[string]interface{}{
+				"main": map[string]interface{}{"port": "y"},
+			},
+		},
+	})
+
+	var paths []string
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case *DecodeError:
+			for _, sub := range e.Errors {
+				walk(sub)
+			}
+		case *FieldError:
+			paths = append(paths, e.Path)
+			walk(errors.Unwrap(e))
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	report(nil, []string{
+		"Server",
+		"Server.Listeners", "Server.Listeners[2].Port",
+		"Server.Limits", "Server.Limits[main].Port",
+	}, paths, t)
+	if err == nil || !strings.Contains(err.Error(), `field Server.Listeners[2].Port (int) from key "port"`) {
+		t.Errorf("expected full path in error, got %v", err)
+	}
+}
+
+func Test_Struct_required(t *testing.T) {
+	type db struct {
+		DSN string `coerce:",required"`
+	}
+	var o struct {
+		Name    string `coerce:",required"`
+		Port    int    `coerce:"--port,required"`
+		Verbose bool
+		DB      db
+	}
+	err := Struct(&o, map[string]interface{}{"name": "x", "--port": "80", "db": map[string]interface{}{"dsn": "d"}})
+	report(err, "x", o.Name, t)
+	report(err, 80, o.Port, t)
+
+	err = Struct(&o, map[string]interface{}{"verbose": true, "db": map[string]interface{}{}})
+	if !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected ErrRequired, got %v", err)
+	}
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected *DecodeError, got %T", err)
+	}
+	report(nil, []string{"Name", "Port", "DB"}, de.Failed, t)
+	report(nil, []string{"Verbose"}, de.Assigned, t)
+	if !strings.Contains(err.Error(), "field DB.DSN: required: ") {
+		t.Errorf("expected nested required error, got %v", err)
+	}
+}
+
+func Test_Struct_default(t *testing.T) {
+	type x struct {
+		Port    int           `coerce:",default=8080"`
+		Host    string        `coerce:"--host,default=localhost"`
+		Timeout time.Duration `coerce:",default=30s"`
+		Ratio   float64       `coerce:",default=50%"`
+		Name    string        `coerce:",required,default=anon"`
+		Other   int
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{"timeout": nil, "other": 1}) // nil takes the default too
+	report(err, x{8080, "localhost", 30 * time.Second, 0.5, "anon", 1}, myx, t)
+
+	myx = x{}
+	err = Struct(&myx, map[string]interface{}{"port": "9090", "--host": "example.com"})
+	report(err, 9090, myx.Port, t)
+	report(err, "example.com", myx.Host, t)
+
+	var bad struct {
+		Port int `coerce:",default=eighty"`
+	}
+	if err := Struct(&bad, map[string]interface{}{}); err == nil {
+		t.Error("expected error for invalid default")
+	}
+}
+
+func Test_Struct_remain(t *testing.T) {
+	type plugin struct {
+		Name  string
+		Extra map[string]interface{} `coerce:",remain"`
+	}
+	var p plugin
+	from := map[string]interface{}{"name": "p", "level": 3, "mode": "fast"}
+	err := NewDecoder(ErrorOnUnusedKeys()).Decode(&p, from)
+	report(err, plugin{"p", map[string]interface{}{"level": 3, "mode": "fast"}}, p, t)
+
+	m, err := Map(p, "%s")
+	report(err, map[string]interface{}{"Name": "p", "level": 3, "mode": "fast"}, m, t)
+
+	var typed struct {
+		Name  string
+		Sizes map[string]int `coerce:",remain"`
+	}
+	err = Struct(&typed, map[string]interface{}{"name": "t", "a": "1k", "b": 2.0})
+	report(err, map[string]int{"a": 1024, "b": 2}, typed.Sizes, t)
+
+	err = Struct(&typed, map[string]interface{}{"c": "x"})
+	if err == nil || !strings.Contains(err.Error(), "field Sizes (map[string]int): can't coerce") {
+		t.Errorf("expected remain error, got %v", err)
+	}
+}
+
+func Test_Struct_alias(t *testing.T) {
+	type x struct {
+		Timeout int `coerce:"timeout,alias=timeout_secs,alias=ttl"`
+		Retries int `coerce:",alias=max_retries"`
+	}
+	for _, tc := range []struct {
+		from map[string]interface{}
+		want x
+	}{
+		{map[string]interface{}{"timeout": 1, "ttl": 3}, x{Timeout: 1}},
+		{map[string]interface{}{"timeout_secs": 2, "ttl": 3}, x{Timeout: 2}},
+		{map[string]interface{}{"ttl": 3}, x{Timeout: 3}},
+		{map[string]interface{}{"retries": 4, "max_retries": 5}, x{Retries: 4}},
+		{map[string]interface{}{"max_retries": 5}, x{Retries: 5}},
+	} {
+		var myx x
+		err := NewDecoder(ErrorOnUnusedKeys()).Decode(&myx, tc.from)
+		if len(tc.from) > 1 {
+			// the unused alias is reported
+			if err == nil || !strings.Contains(err.Error(), "unused keys") {
+				t.Errorf("expected unused key error, got %v", err)
+			}
+			err = nil
+		}
+		report(err, tc.want, myx, t)
+	}
+
+	var myx x
+	err := Args(&myx, []string{"--ttl", "7", "--max_retries=2"})
+	report(err, x{7, 2}, myx, t)
+}
@@ -0,0 +1,78 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Coercer decodes maps into one struct type using a precomputed plan of
+// the fields and the keys they may match, for services which decode many
+// maps into the same type
+type Coercer struct {
+	d       *Decoder
+	t       reflect.Type // the struct type
+	keys    [][]string   // candidate keys per field of d.fields(t)
+	version [2]uint64    // convertersVersion when compiled
+}
+
+// Compile returns a Coercer for struct type t (or pointer to struct),
+// matching keys formatted as per Struct
+func Compile(t reflect.Type, formats ...string) (*Coercer, error) {
+	return NewDecoder(Formats(formats...)).Compile(t)
+}
+
+// Compile returns a Coercer for struct type t (or pointer to struct) which
+// decodes using the Decoder's options
+func (d *Decoder) Compile(t reflect.Type) (*Coercer, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct type, got %v", t)
+	}
+
+	fields := d.fields(t)
+	keys := make([][]string, len(fields))
+	for i, f := range fields {
+		if d.keyNames != nil {
+			keys[i] = d.keyNames(f.Name)
+		} else {
+			keys[i] = candidateKeys(f.Name, d.formats)
+		}
+	}
+	return &Coercer{d, t, keys, d.convertersVersion()}, nil
+}
+
+// Decode coerces the values in 'from' into the structure pointed to by
+// 'to', which must be of the Coercer's type; see Struct
+func (c *Coercer) Decode(to interface{}, from map[string]interface{}) error {
+	if pt := reflect.TypeOf(to); pt == nil || pt.Kind() != reflect.Ptr || pt.Elem() != c.t {
+		return errorf(ErrCannotSet, "expected *%v for 'to', got %T", c.t, to)
+	}
+	keys := c.keys
+	if c.d.convertersVersion() != c.version {
+		// converters registered since may change which structs are
+		// squashed, and so the fields the keys were computed for
+		keys = nil
+	}
+	return c.d.decode(to, from, nil, keys)
+}
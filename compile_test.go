@@ -0,0 +1,81 @@
+package coerce
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Compile(t *testing.T) {
+	type x struct {
+		MaxRetries int
+		DryRun     bool `coerce:"--dry"`
+		Name       string
+	}
+
+	c, err := Compile(reflect.TypeOf(x{}), "--%s", "-%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		var myx x
+		err = c.Decode(&myx, map[string]interface{}{
+			"--max-retries": "5",
+			"--dry":         "true",
+			"-name":         "n",
+		})
+		report(err, x{5, true, "n"}, myx, t)
+	}
+
+	var other struct{ Name string }
+	if err := c.Decode(&other, nil); err == nil {
+		t.Errorf("expected error for wrong target type")
+	}
+	if _, err := Compile(reflect.TypeOf(1)); err == nil {
+		t.Errorf("expected error for non-struct type")
+	}
+}
+
+func Benchmark_Compile(b *testing.B) {
+	type x struct {
+		MaxRetries int
+		Verbose    bool
+		Name       string
+		Ratio      float64
+	}
+	from := map[string]interface{}{
+		"--max-retries": "5",
+		"--verbose":     true,
+		"--name":        "widget",
+		"--ratio":       0.5,
+	}
+	c, _ := Compile(reflect.TypeOf(x{}), "--%s")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var myx x
+		if err := c.Decode(&myx, from); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Test_Compile_converter_registered(t *testing.T) {
+	type x struct {
+		Coord
+		Name string
+	}
+	c, err := Compile(reflect.TypeOf(x{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	RegisterConverter(reflect.TypeOf(Coord{}), func(from interface{}) (interface{}, error) {
+		return Coord{1, 2}, nil
+	})
+	defer RegisterConverter(reflect.TypeOf(Coord{}), nil)
+
+	var myx x
+	err = c.Decode(&myx, map[string]interface{}{"coord": "1,2", "name": "n"})
+	report(err, x{Coord{1, 2}, "n"}, myx, t)
+}
@@ -0,0 +1,109 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Converter converts an arbitrary source value into a value of the type it
+// was registered for
+type Converter func(from interface{}) (interface{}, error)
+
+var (
+	convertersMu  sync.RWMutex
+	converters    = map[reflect.Type]Converter{}
+	convertersGen uint64 // bumped by RegisterConverter, for the fields cache
+
+	lastConvertersID uint64 // as given to Decoders by Converters
+)
+
+// RegisterConverter registers fn to handle coercion of any value into
+// targetType.  Registered converters take precedence over the built-in
+// conversions (but not over direct assignment), so they can be used to
+// teach coerce about third-party types without a hard dependency, or to
+// replace coerce's own parsing of types such as time.Duration.  They apply
+// to both Struct and Var.
+// Registering a nil fn removes any converter for targetType.
+func RegisterConverter(targetType reflect.Type, fn func(from interface{}) (interface{}, error)) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	convertersGen++
+	if fn == nil {
+		delete(converters, targetType)
+		return
+	}
+	converters[targetType] = fn
+}
+
+// Converters gives the Decoder its own converters, as per
+// RegisterConverter, which take precedence over those registered globally
+func Converters(converters map[reflect.Type]Converter) Option {
+	return func(d *Decoder) {
+		if d.converters == nil {
+			d.converters = map[reflect.Type]Converter{}
+		}
+		d.convertersID = atomic.AddUint64(&lastConvertersID, 1)
+		for t, c := range converters {
+			d.converters[t] = c
+		}
+	}
+}
+
+// lookupConverter returns the Decoder's converter for t, or failing that
+// the registered one, if any
+func (d *Decoder) lookupConverter(t reflect.Type) (Converter, bool) {
+	if c, ok := d.converters[t]; ok {
+		return c, true
+	}
+	return lookupConverter(t)
+}
+
+// convertersVersion identifies the converters the Decoder sees, changing
+// whenever they do
+func (d *Decoder) convertersVersion() [2]uint64 {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	return [2]uint64{convertersGen, d.convertersID}
+}
+
+// lookupConverter returns the registered converter for t, if any
+func lookupConverter(t reflect.Type) (Converter, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	c, ok := converters[t]
+	return c, ok
+}
+
+// convert applies converter c to vfrom and stores the result in vto
+func convert(c Converter, vto reflect.Value, vfrom reflect.Value) error {
+	result, err := c(vfrom.Interface())
+	if err != nil {
+		return err
+	}
+	vr := reflect.ValueOf(result)
+	if !vr.IsValid() || !vr.Type().AssignableTo(vto.Type()) {
+		return errorf(ErrUnsupportedConversion, "converter for %v returned %T", vto.Type(), result)
+	}
+	vto.Set(vr)
+	return nil
+}
@@ -0,0 +1,73 @@
+package coerce
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type upper string
+
+func Test_RegisterConverter(t *testing.T) {
+	RegisterConverter(reflect.TypeOf(upper("")), func(from interface{}) (interface{}, error) {
+		return upper(strings.ToUpper(String(from))), nil
+	})
+	defer RegisterConverter(reflect.TypeOf(upper("")), nil)
+
+	var u upper
+	err := Var(&u, "shout")
+	report(err, upper("SHOUT"), u, t)
+}
+
+func Test_RegisterConverter_override(t *testing.T) {
+	durationType := reflect.TypeOf(time.Duration(0))
+	RegisterConverter(durationType, func(from interface{}) (interface{}, error) {
+		secs, err := Int(from)
+		return time.Duration(secs) * time.Second, err
+	})
+
+	type x struct {
+		Timeout time.Duration
+	}
+	var myx x
+	err := Struct(&myx, map[string]interface{}{"timeout": "30"})
+	report(err, x{30 * time.Second}, myx, t)
+
+	RegisterConverter(durationType, nil)
+	err = Struct(&myx, map[string]interface{}{"timeout": "1m"})
+	report(err, x{time.Minute}, myx, t)
+}
+
+type Coord struct {
+	X, Y int
+}
+
+func Test_RegisterConverter_embedded(t *testing.T) {
+	type x struct {
+		Coord
+		Name string
+	}
+	parse := func(from interface{}) (interface{}, error) {
+		var p Coord
+		_, err := fmt.Sscanf(String(from), "%d,%d", &p.X, &p.Y)
+		return p, err
+	}
+
+	// squashed until a converter parses Coord as a whole
+	var myx x
+	err := Struct(&myx, map[string]interface{}{"x": 1, "y": 2})
+	report(err, x{Coord{1, 2}, ""}, myx, t)
+
+	RegisterConverter(reflect.TypeOf(Coord{}), parse)
+	myx = x{}
+	err = Struct(&myx, map[string]interface{}{"Coord": "3,4"})
+	report(err, x{Coord{3, 4}, ""}, myx, t)
+	RegisterConverter(reflect.TypeOf(Coord{}), nil)
+
+	myx = x{}
+	d := NewDecoder(Converters(map[reflect.Type]Converter{reflect.TypeOf(Coord{}): parse}))
+	err = d.Decode(&myx, map[string]interface{}{"Coord": "5,6", "name": "n"})
+	report(err, x{Coord{5, 6}, "n"}, myx, t)
+}
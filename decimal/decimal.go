@@ -0,0 +1,128 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+// Package decimal provides a fixed-point Decimal type and registers a
+// coerce converter for it, so that monetary and other precise values can
+// be coerced from strings without float64 rounding.  Import it for its
+// side effects if only the registration is wanted:
+//
+//	import _ "github.com/SeeSpotRun/coerce/decimal"
+//
+// Other decimal types (eg github.com/shopspring/decimal) can be supported
+// the same way via coerce.RegisterConverter.
+package decimal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/SeeSpotRun/coerce"
+)
+
+func init() {
+	coerce.RegisterConverter(reflect.TypeOf(Decimal{}), Convert)
+}
+
+// Decimal is a fixed-point number equal to Unscaled * 10^-Scale, eg
+// Decimal{12345, 2} is 123.45
+type Decimal struct {
+	Unscaled int64
+	Scale    int
+}
+
+// Parse parses a plain decimal string such as "-1234.50"; exponents are
+// not accepted.  The scale is the number of digits after the point.
+func Parse(s string) (Decimal, error) {
+	t := strings.TrimSpace(s)
+	whole, frac := t, ""
+	if dot := strings.Index(t, "."); dot >= 0 {
+		whole, frac = t[:dot], t[dot+1:]
+	}
+	if strings.ContainsAny(frac, "+-") || whole == "" && frac == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	if whole == "" || whole == "-" || whole == "+" {
+		whole += "0"
+	}
+	u, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal{Unscaled: u, Scale: len(frac)}, nil
+}
+
+// String formats d with exactly Scale fractional digits
+func (d Decimal) String() string {
+	if d.Scale <= 0 {
+		return strconv.FormatInt(d.Unscaled, 10)
+	}
+	digits := strconv.FormatInt(d.Unscaled, 10)
+	sign := ""
+	if digits[0] == '-' {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.Scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Float64 returns the nearest float64 to d
+func (d Decimal) Float64() float64 {
+	return float64(d.Unscaled) / math.Pow10(d.Scale)
+}
+
+// Convert coerces strings, []byte, json.Number, integers and floats into
+// a Decimal.  Floats are converted via their shortest decimal
+// representation, so 0.1 becomes exactly 0.1.
+func Convert(from interface{}) (interface{}, error) {
+	switch v := from.(type) {
+	case Decimal:
+		return v, nil
+	case string:
+		return Parse(v)
+	case []byte:
+		return Parse(string(v))
+	case json.Number:
+		return Parse(v.String())
+	case float32:
+		return Parse(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case float64:
+		return Parse(strconv.FormatFloat(v, 'f', -1, 64))
+	}
+
+	rv := reflect.ValueOf(from)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Decimal{Unscaled: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%v overflows decimal", from)
+		}
+		return Decimal{Unscaled: int64(rv.Uint())}, nil
+	case reflect.String:
+		return Parse(rv.String())
+	}
+	return nil, fmt.Errorf("can't coerce %T to decimal.Decimal", from)
+}
@@ -0,0 +1,50 @@
+package decimal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SeeSpotRun/coerce"
+)
+
+func Test_Struct_decimal(t *testing.T) {
+	type invoice struct {
+		Total Decimal
+		Tax   Decimal
+		Count Decimal
+	}
+
+	var inv invoice
+	err := coerce.Struct(&inv, map[string]interface{}{
+		"total": "1234.50",
+		"tax":   0.1,
+		"count": 3,
+	})
+
+	expected := invoice{Decimal{123450, 2}, Decimal{1, 1}, Decimal{3, 0}}
+	if err != nil || !reflect.DeepEqual(expected, inv) {
+		t.Errorf("expected %v, got %v (%v)", expected, inv, err)
+	}
+	if s := inv.Total.String(); s != "1234.50" {
+		t.Errorf("expected 1234.50, got %s", s)
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	for s, expected := range map[string]string{
+		"-0.05": "-0.05",
+		".5":    "0.5",
+		"-.5":   "-0.5",
+		"42":    "42",
+	} {
+		d, err := Parse(s)
+		if err != nil || d.String() != expected {
+			t.Errorf("Parse(%q): expected %s, got %v (%v)", s, expected, d, err)
+		}
+	}
+	for _, s := range []string{"", ".", "1.2.3", "1e5", "1.-2"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q): expected error", s)
+		}
+	}
+}
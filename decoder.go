@@ -0,0 +1,468 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"math"
+	"reflect"
+	"time"
+)
+
+// Decoder coerces values according to a set of options.  Options are
+// supplied to NewDecoder; the package-level functions use a Decoder with
+// default options.
+type Decoder struct {
+	formats      []string
+	copyOnAssign bool
+	location     *time.Location
+	timeLayouts  []string
+	epochUnit    time.Duration
+	strictBool   bool
+
+	keyNames         func(field string) []string
+	matchKey         func(field, key string) bool
+	foldKeys         bool
+	nestSep          string
+	errorOnAmbiguous bool
+	errorOnUnused    bool
+	errorOnMissing   bool
+	warnings         func(Warning)
+
+	tagName        string
+	tagFallback    []string
+	skipUnexported bool
+	converters     map[reflect.Type]Converter
+	convertersID   uint64 // distinguishes the converters map in caches
+	hooks          []DecodeHook
+
+	nonFinite    nonFinitePolicy
+	nonFiniteVal float64
+
+	clampNegative bool
+	saturate      bool
+	ipRanges      bool
+	rounding      RoundingMode
+	siBytes       bool
+	keepPercent   bool
+
+	separator   string
+	kvSeparator string
+
+	unions     map[reflect.Type]union
+	limits     Limits
+	migrations []Migration
+}
+
+// nonFinitePolicy determines the treatment of NaN and infinite floats
+type nonFinitePolicy int
+
+const (
+	allowNonFinite nonFinitePolicy = iota
+	rejectNonFinite
+	replaceNonFinite
+)
+
+// Warning describes a non-fatal problem noticed while decoding
+type Warning struct {
+	Field   string // struct field concerned
+	Key     string // map key concerned
+	Message string
+
+	// Deprecated holds the hint from a `coerce:",deprecated=hint"` tag
+	// when the warning is about use of a deprecated key
+	Deprecated string
+}
+
+// String returns the warning message
+func (w Warning) String() string {
+	return w.Message
+}
+
+// Option configures a Decoder
+type Option func(*Decoder)
+
+var defaultDecoder = NewDecoder()
+
+// NewDecoder returns a Decoder configured by opts
+func NewDecoder(opts ...Option) *Decoder {
+	d := &Decoder{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Formats sets the formats used to morph field names into map keys, as
+// per Struct
+func Formats(formats ...string) Option {
+	return func(d *Decoder) {
+		d.formats = formats
+	}
+}
+
+// TagName makes the Decoder read field tags under key name rather than
+// "coerce", eg TagName("json") to reuse `json:"name"` tags for key names.
+// Fields tagged with the name "-" are skipped.
+func TagName(name string) Option {
+	return func(d *Decoder) {
+		d.tagName = name
+	}
+}
+
+// Separator sets the delimiter on which string values are split into the
+// elements of slice fields; the default is ","
+func Separator(sep string) Option {
+	return func(d *Decoder) {
+		d.separator = sep
+	}
+}
+
+// KeyValueSeparator sets the delimiter between the keys and values of the
+// "key=value" strings from which map fields may be coerced, eg for labels
+// such as "env=prod,tier=web"; the default is "="
+func KeyValueSeparator(sep string) Option {
+	return func(d *Decoder) {
+		d.kvSeparator = sep
+	}
+}
+
+// TagFallback makes the Decoder take key names from the first present of
+// the given tags, eg TagFallback("json", "yaml", "toml"), for fields with
+// no coerce tag (or TagName tag).  Only the name is used, not the options;
+// fields tagged with the name "-" are skipped.
+func TagFallback(names ...string) Option {
+	return func(d *Decoder) {
+		d.tagFallback = names
+	}
+}
+
+// SkipUnexported makes the Decoder ignore unexported struct fields, as
+// encoding/json does, rather than setting them via 'unsafe' pointers, bar
+// those with setter methods (see Struct) and the exported fields of
+// embedded structs, which are set as usual
+func SkipUnexported() Option {
+	return func(d *Decoder) {
+		d.skipUnexported = true
+	}
+}
+
+// CopyOnAssign makes the Decoder copy slice and map values (recursively)
+// even when they could be assigned directly, so that the decoded target
+// never aliases storage belonging to the source
+func CopyOnAssign() Option {
+	return func(d *Decoder) {
+		d.copyOnAssign = true
+	}
+}
+
+// Location sets the time zone applied when parsing timestamps which carry
+// no zone information, eg "2024-01-02 15:04"; the default is UTC
+func Location(loc *time.Location) Option {
+	return func(d *Decoder) {
+		d.location = loc
+	}
+}
+
+// TimeLayouts sets layouts for the Decoder to try first when parsing
+// strings into time.Time values; see RegisterTimeLayouts
+func TimeLayouts(layouts ...string) Option {
+	return func(d *Decoder) {
+		d.timeLayouts = layouts
+	}
+}
+
+// EpochUnit sets the unit of numeric Unix timestamps coerced into
+// time.Time values, eg time.Millisecond for JavaScript-style timestamps;
+// the default is time.Second
+func EpochUnit(unit time.Duration) Option {
+	return func(d *Decoder) {
+		d.epochUnit = unit
+	}
+}
+
+// StrictBool makes the Decoder accept only the exact strings "true" and
+// "false" for bool targets, rather than the likes of "1" or "T"
+func StrictBool() Option {
+	return func(d *Decoder) {
+		d.strictBool = true
+	}
+}
+
+// KeyNames sets a function giving the keys to try, in order of
+// precedence, for each field name, in place of formats and the usual name
+// variants, for naming rules which formats can't express, eg
+//
+//	coerce.KeyNames(func(field string) []string {
+//		return []string{strings.ToLower(strings.Replace(field, "ID", "Id", -1))}
+//	})
+//
+// Fields whose tags name their key are unaffected.
+func KeyNames(fn func(field string) []string) Option {
+	return func(d *Decoder) {
+		d.keyNames = fn
+	}
+}
+
+// MatchKeys sets a function deciding whether a map key matches a field
+// name, tried against each key (in sorted order) for fields which match
+// no key otherwise
+func MatchKeys(fn func(field, key string) bool) Option {
+	return func(d *Decoder) {
+		d.matchKey = fn
+	}
+}
+
+// FoldKeys makes the Decoder match keys regardless of case, hyphens and
+// underscores when no key matches as per Struct, so that field MaxRetries
+// is also set by eg "MAXRETRIES", "MAX_RETRIES" or "max-Retries"
+func FoldKeys() Option {
+	return func(d *Decoder) {
+		d.foldKeys = true
+	}
+}
+
+// NestedKeys makes the Decoder fill struct (and map) fields which match no
+// key from the flat keys which join the field's key and those of its own
+// fields with sep, eg with sep "." the keys "db.host" and "db.pool.max"
+// set fields DB.Host and DB.Pool.Max, as from Java-style properties.
+// Each part is matched as per Struct, so "db.max-conns" sets DB.MaxConns.
+// Env uses "_" unless given another separator.
+func NestedKeys(sep string) Option {
+	return func(d *Decoder) {
+		d.nestSep = sep
+	}
+}
+
+// ErrorOnAmbiguousKeys makes it an error for a field to match more than one
+// key present in the map (eg both "--verbose" and "-verbose"), rather than
+// using the first by order of precedence
+func ErrorOnAmbiguousKeys() Option {
+	return func(d *Decoder) {
+		d.errorOnAmbiguous = true
+	}
+}
+
+// ErrorOnUnusedKeys makes it an error for the map to hold keys which match
+// no field, so that typos in configuration files and command lines are
+// caught rather than silently ignored.  The error suggests the nearest
+// known key for any which look like typos, eg
+//
+//	unused keys ["--intslise"] (did you mean "--intslice" for "--intslise"?)
+func ErrorOnUnusedKeys() Option {
+	return func(d *Decoder) {
+		d.errorOnUnused = true
+	}
+}
+
+// ErrorOnMissingKeys makes it an error for a field to match no key in the
+// map.  By default such fields are left untouched, so that a partial map
+// can be applied over a struct of defaults.
+func ErrorOnMissingKeys() Option {
+	return func(d *Decoder) {
+		d.errorOnMissing = true
+	}
+}
+
+// Warnings sets a function to be called with each non-fatal problem the
+// Decoder notices, such as ambiguous keys or use of keys tagged as
+// deprecated, eg
+//
+//	OldName string        `coerce:",deprecated=use --new-name"`
+//	Timeout time.Duration `coerce:"timeout,alias=ttl,deprecated=use timeout"`
+//
+// where a field with aliases is deprecated only when set by an alias.
+func Warnings(fn func(Warning)) Option {
+	return func(d *Decoder) {
+		d.warnings = fn
+	}
+}
+
+// CollectWarnings makes the Decoder append each warning to *list, as an
+// alternative to Warnings
+func CollectWarnings(list *[]Warning) Option {
+	return Warnings(func(w Warning) {
+		*list = append(*list, w)
+	})
+}
+
+// RejectNonFinite makes it an error to coerce NaN or infinite values
+// (including strings such as "NaN" and "Inf") into float fields
+func RejectNonFinite() Option {
+	return func(d *Decoder) {
+		d.nonFinite = rejectNonFinite
+	}
+}
+
+// ReplaceNonFinite makes the Decoder store f in place of any NaN or
+// infinite value destined for a float field
+func ReplaceNonFinite(f float64) Option {
+	return func(d *Decoder) {
+		d.nonFinite = replaceNonFinite
+		d.nonFiniteVal = f
+	}
+}
+
+// IPRanges makes the Decoder expand ranges such as "10.0.0.1-10.0.0.5" in
+// []net.IP sources into the addresses they cover, up to 65536 addresses
+// per list.  Without it such ranges are invalid addresses.
+func IPRanges() Option {
+	return func(d *Decoder) {
+		d.ipRanges = true
+	}
+}
+
+// ClampNegativeUnsigned makes the Decoder store zero when a negative value
+// is coerced into an unsigned field, rather than returning an error
+func ClampNegativeUnsigned() Option {
+	return func(d *Decoder) {
+		d.clampNegative = true
+	}
+}
+
+// Saturate makes out-of-range numeric conversions clamp to the target
+// type's minimum or maximum instead of failing or wrapping around, eg
+// "300" into an int8 field gives 127 and -5 into a uint gives 0
+func Saturate() Option {
+	return func(d *Decoder) {
+		d.saturate = true
+	}
+}
+
+// SIByteUnits makes the Decoder read bare size suffixes as decimal, eg
+// "10M" as 10,000,000 like "10MB", rather than as binary like "10MiB"
+// (10 * 1024 * 1024)
+func SIByteUnits() Option {
+	return func(d *Decoder) {
+		d.siBytes = true
+	}
+}
+
+// KeepPercent makes the Decoder strip the "%" from percentages coerced
+// into float fields without scaling them, so that "75%" gives 75 rather
+// than 0.75
+func KeepPercent() Option {
+	return func(d *Decoder) {
+		d.keepPercent = true
+	}
+}
+
+// Rounding sets how fractional values are rounded when coerced into
+// integer fields; the default is RoundTruncate
+func Rounding(mode RoundingMode) Option {
+	return func(d *Decoder) {
+		d.rounding = mode
+	}
+}
+
+// Decode attempts to unmarshall the values in 'from' into the fields in
+// the structure pointed to by 'to'; see Struct
+func (d *Decoder) Decode(to interface{}, from map[string]interface{}) error {
+	return d.decode(to, from, nil, nil)
+}
+
+// decode is Decode, also recording what was done in md if that is non-nil,
+// using the candidate keys precomputed by Compile if keys is non-nil
+func (d *Decoder) decode(to interface{}, from map[string]interface{}, md *Metadata, keys [][]string) error {
+
+	// get target as reflect.Value and check kind:
+	pt := reflect.ValueOf(to)
+	vt := reflect.Indirect(pt)
+	if vt.Kind() != reflect.Struct || pt.Kind() != reflect.Ptr {
+		return errorf(ErrCannotSet, "expected *struct for 'to', got %v", pt.Kind())
+	}
+
+	if err := d.checkLimits(from); err != nil {
+		return err
+	}
+
+	from, err := d.migrate(from)
+	if err != nil {
+		return err
+	}
+
+	return d.decodeStruct(vt, from, d.formats, md, keys)
+}
+
+// Var attempts to cast the content of 'from' into the variable pointed to
+// by 'pto'
+func (d *Decoder) Var(pto interface{}, from interface{}) error {
+	if err := d.checkLimits(from); err != nil {
+		return err
+	}
+	return d.unmarshall(reflect.Indirect(reflect.ValueOf(pto)), reflect.ValueOf(from))
+}
+
+// finite applies the Decoder's NaN/Inf policy to f
+func (d *Decoder) finite(f float64) (float64, error) {
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f, nil
+	}
+	switch d.nonFinite {
+	case rejectNonFinite:
+		return 0, errorf(ErrInvalidValue, "non-finite value %v not allowed", f)
+	case replaceNonFinite:
+		return d.nonFiniteVal, nil
+	}
+	return f, nil
+}
+
+// warn passes w to the Decoder's warning function, if any
+func (d *Decoder) warn(w Warning) {
+	if d.warnings != nil {
+		d.warnings(w)
+	}
+}
+
+// copyValue returns a copy of v in which any slices and maps (including
+// those nested in slices, maps and interfaces) are freshly allocated
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+		}
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyValue(v.Elem()))
+		return c
+	}
+	return v
+}
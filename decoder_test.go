@@ -0,0 +1,331 @@
+package coerce
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Decoder_CopyOnAssign(t *testing.T) {
+	type x struct {
+		Tags   []string
+		Labels map[string]interface{}
+	}
+
+	tags := []string{"a", "b"}
+	labels := map[string]interface{}{"env": []string{"prod"}}
+
+	var myx x
+	err := NewDecoder(CopyOnAssign()).Decode(&myx, map[string]interface{}{
+		"tags":   tags,
+		"labels": labels,
+	})
+	report(err, x{tags, labels}, myx, t)
+
+	tags[0] = "changed"
+	labels["env"].([]string)[0] = "changed"
+	report(nil, x{[]string{"a", "b"}, map[string]interface{}{"env": []string{"prod"}}}, myx, t)
+}
+
+func Test_Decoder_StrictBool(t *testing.T) {
+	var b bool
+	err := Var(&b, "1")
+	report(err, true, b, t)
+
+	strict := NewDecoder(StrictBool())
+	err = strict.Var(&b, "false")
+	report(err, false, b, t)
+
+	for _, s := range []string{"1", "TRUE", "True", "t"} {
+		if err := strict.Var(&b, s); err == nil {
+			t.Errorf("expected strict error for %q", s)
+		}
+	}
+}
+
+func Test_Decoder_ambiguous_keys(t *testing.T) {
+	type x struct {
+		MaxRetries int
+	}
+	mymap := map[string]interface{}{
+		"--max-retries": 3,
+		"--max_retries": 4,
+		"-MaxRetries":   5,
+	}
+
+	var warnings []Warning
+	var myx x
+	err := NewDecoder(Formats("-%s", "--%s"), Warnings(func(w Warning) {
+		warnings = append(warnings, w)
+	})).Decode(&myx, mymap)
+	report(err, x{5}, myx, t)
+	if len(warnings) != 1 || warnings[0].Key != "-MaxRetries" {
+		t.Errorf("expected one warning for -MaxRetries, got %v", warnings)
+	}
+
+	err = NewDecoder(Formats("-%s", "--%s"), ErrorOnAmbiguousKeys()).Decode(&myx, mymap)
+	if err == nil {
+		t.Errorf("expected ambiguity error")
+	}
+}
+
+func Test_Decoder_deprecated_keys(t *testing.T) {
+	type x struct {
+		NewName string
+		OldName string `coerce:",deprecated=use --new-name"`
+	}
+
+	var warnings []Warning
+	var myx x
+	err := NewDecoder(Formats("--%s"), Warnings(func(w Warning) {
+		warnings = append(warnings, w)
+	})).Decode(&myx, map[string]interface{}{"--old-name": "bob"})
+	report(err, x{OldName: "bob"}, myx, t)
+
+	expected := []Warning{{
+		Field:      "OldName",
+		Key:        "--old-name",
+		Message:    `field OldName: key "--old-name" is deprecated: use --new-name`,
+		Deprecated: "use --new-name",
+	}}
+	report(nil, expected, warnings, t)
+}
+
+func Test_Decoder_deprecated_aliases(t *testing.T) {
+	type x struct {
+		Timeout int `coerce:"timeout,alias=ttl,alias=timeout_secs,deprecated=use timeout"`
+	}
+
+	var warnings []Warning
+	d := NewDecoder(CollectWarnings(&warnings))
+	var myx x
+	err := d.Decode(&myx, map[string]interface{}{"timeout": 1})
+	report(err, 1, myx.Timeout, t)
+	report(nil, 0, len(warnings), t)
+
+	err = d.Decode(&myx, map[string]interface{}{"ttl": 2})
+	report(err, 2, myx.Timeout, t)
+	report(nil, []Warning{{
+		Field:      "Timeout",
+		Key:        "ttl",
+		Message:    `field Timeout: key "ttl" is deprecated: use timeout`,
+		Deprecated: "use timeout",
+	}}, warnings, t)
+}
+
+func Test_Decoder_NonFinite(t *testing.T) {
+	var f float64
+	err := Var(&f, "NaN")
+	if err != nil || !math.IsNaN(f) {
+		t.Errorf("expected NaN, got %v (%v)", f, err)
+	}
+
+	if err := NewDecoder(RejectNonFinite()).Var(&f, math.Inf(1)); err == nil {
+		t.Errorf("expected error for +Inf")
+	}
+
+	var f32 float32
+	err = NewDecoder(ReplaceNonFinite(-1)).Var(&f32, "-Inf")
+	report(err, float32(-1), f32, t)
+
+	err = NewDecoder(RejectNonFinite()).Var(&f32, 2.5)
+	report(err, float32(2.5), f32, t)
+}
+
+func Test_Decoder_negative_unsigned(t *testing.T) {
+	var u uint16
+	for _, from := range []interface{}{-1.5, "-3", "-1k"} {
+		if err := Var(&u, from); err == nil {
+			t.Errorf("expected error for %#v, got %v", from, u)
+		}
+
+		u = 9
+		err := NewDecoder(ClampNegativeUnsigned()).Var(&u, from)
+		report(err, uint16(0), u, t)
+	}
+}
+
+func Test_Decoder_TagName_Converters(t *testing.T) {
+	type x struct {
+		Name    string `json:"full_name"`
+		Skip    int    `json:"-"`
+		Timeout time.Duration
+	}
+
+	secs := func(from interface{}) (interface{}, error) {
+		n, err := Int(from)
+		return time.Duration(n) * time.Second, err
+	}
+
+	var myx x
+	err := NewDecoder(TagName("json"), Converters(map[reflect.Type]Converter{
+		reflect.TypeOf(time.Duration(0)): secs,
+	})).Decode(&myx, map[string]interface{}{
+		"full_name": "Ann",
+		"-":         "1",
+		"timeout":   "5",
+	})
+	report(err, x{"Ann", 0, 5 * time.Second}, myx, t)
+
+	// the global default is unaffected
+	err = Struct(&myx, map[string]interface{}{"timeout": "5m"})
+	report(err, x{"Ann", 0, 5 * time.Minute}, myx, t)
+}
+
+func Test_Decoder_unused_keys(t *testing.T) {
+	type inner struct {
+		Host string
+	}
+	type x struct {
+		Verbose bool
+		Files   []string `coerce:",pos"`
+		Port    int      `coerce:"/server/port"`
+		Inner   inner
+	}
+
+	d := NewDecoder(Formats("--%s"), ErrorOnUnusedKeys())
+	var myx x
+	err := d.Decode(&myx, map[string]interface{}{
+		"--verbose": "true",
+		"0":         "a",
+		"1":         "b",
+		"server":    map[string]interface{}{"port": 80},
+		"--inner":   map[string]interface{}{"host": "h"},
+	})
+	report(err, x{true, []string{"a", "b"}, 80, inner{"h"}}, myx, t)
+
+	err = d.Decode(&myx, map[string]interface{}{
+		"--verbsoe": "true",
+		"--vebrose": "true",
+		"--inner":   map[string]interface{}{"hots": "h"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `unused keys ["--vebrose" "--verbsoe"]`) ||
+		!strings.Contains(err.Error(), `unused keys ["hots"]`) {
+		t.Errorf("expected unused key errors, got %v", err)
+	}
+}
+
+func Test_Decoder_missing_keys(t *testing.T) {
+	type x struct {
+		Host string
+		Port int
+	}
+
+	myx := x{"localhost", 80}
+	err := Struct(&myx, map[string]interface{}{"port": "8080"})
+	report(err, x{"localhost", 8080}, myx, t)
+
+	err = NewDecoder(ErrorOnMissingKeys()).Decode(&myx, map[string]interface{}{"port": "8081"})
+	report(nil, x{"localhost", 8081}, myx, t)
+	de, ok := err.(*DecodeError)
+	if !ok || len(de.Errors) != 1 || de.Errors[0].Error() != "field Host: [Host|host] not found in map" {
+		t.Errorf("expected missing key error for Host, got %v", err)
+	}
+}
+
+func Test_Decoder_FoldKeys(t *testing.T) {
+	type x struct {
+		MaxRetries int
+		DryRun     bool
+		LogLevel   string
+	}
+	for _, keys := range [][3]string{
+		{"max_retries", "dry-run", "loglevel"},
+		{"MAX_RETRIES", "DRYRUN", "Log_Level"},
+		{"maxretries", "Dry_Run", "LOG-LEVEL"},
+	} {
+		var myx x
+		err := NewDecoder(FoldKeys()).Decode(&myx, map[string]interface{}{
+			keys[0]: "3", keys[1]: true, keys[2]: "debug",
+		})
+		report(err, x{3, true, "debug"}, myx, t)
+	}
+
+	var myx x
+	err := NewDecoder(FoldKeys(), Formats("APP_%s")).Decode(&myx, map[string]interface{}{
+		"APP_MAX_RETRIES": "5", "MAX_RETRIES": "6",
+	})
+	report(err, 5, myx.MaxRetries, t)
+
+	myx = x{}
+	err = Struct(&myx, map[string]interface{}{"MAX_RETRIES": "3"})
+	report(err, 0, myx.MaxRetries, t)
+}
+
+func Test_Decoder_KeyNames(t *testing.T) {
+	type x struct {
+		UserID  int
+		HTTPURL string
+		Tagged  string `coerce:"tagged-key"`
+	}
+	snake := func(field string) []string {
+		// keep acronyms together: UserID -> user_id, HTTPURL -> http_url
+		switch field {
+		case "UserID":
+			return []string{"user_id", "uid"}
+		case "HTTPURL":
+			return []string{"http_url"}
+		}
+		return nil
+	}
+	d := NewDecoder(KeyNames(snake), ErrorOnMissingKeys())
+	var myx x
+	err := d.Decode(&myx, map[string]interface{}{"uid": "7", "http_url": "http://x", "tagged-key": "t"})
+	report(err, x{7, "http://x", "t"}, myx, t)
+
+	c, err := d.Compile(reflect.TypeOf(x{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	myx = x{}
+	err = c.Decode(&myx, map[string]interface{}{"user_id": 8, "http_url": "h", "tagged-key": "t"})
+	report(err, x{8, "h", "t"}, myx, t)
+
+	err = d.Decode(&myx, map[string]interface{}{"http_url": "h", "tagged-key": "t"})
+	if err == nil || !strings.Contains(err.Error(), "[user_id|uid] not found in map") {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+}
+
+func Test_Decoder_MatchKeys(t *testing.T) {
+	type x struct {
+		Timeout int
+		Retries int
+	}
+	prefix := func(field, key string) bool {
+		// accept any abbreviation of three or more letters
+		f := strings.ToLower(field)
+		return len(key) >= 3 && strings.HasPrefix(f, strings.ToLower(key))
+	}
+	var myx x
+	err := NewDecoder(MatchKeys(prefix)).Decode(&myx, map[string]interface{}{"time": 5, "Retries": 2, "re": 9})
+	report(err, x{5, 2}, myx, t)
+}
+
+func Test_Decoder_TagFallback(t *testing.T) {
+	type x struct {
+		Name    string `json:"full_name,omitempty"`
+		Port    int    `yaml:"listen_port"`
+		Both    string `coerce:"both-coerce" json:"both_json"`
+		Skipped string `json:"-"`
+		Plain   string `json:",omitempty"`
+	}
+	d := NewDecoder(TagFallback("json", "yaml"))
+	var myx x
+	err := d.Decode(&myx, map[string]interface{}{
+		"full_name":   "Alice",
+		"listen_port": "80",
+		"both-coerce": "c",
+		"both_json":   "j",
+		"skipped":     "no",
+		"plain":       "p",
+	})
+	report(err, x{"Alice", 80, "c", "", "p"}, myx, t)
+
+	// the default Decoder ignores the fallback tags
+	myx = x{}
+	err = Struct(&myx, map[string]interface{}{"full_name": "Alice", "name": "Bob"})
+	report(err, "Bob", myx.Name, t)
+}
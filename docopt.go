@@ -0,0 +1,88 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Docopt coerces the options map returned by docopt (eg docopt-go's
+// ParseArgs) into the fields of the structure pointed to by 'to'.  Keys of
+// each docopt shape are matched to field names as per Struct, so that
+// field DryRun is set by "--dry-run", field File by "<file>" or "FILE" and
+// field Push by command "push"; fields tagged `coerce:",short=n"` are also
+// set by "-n".  Fields may still be tagged with a literal key, eg
+// `coerce:"--dry"`.
+//
+// Counted flags (eg "-v...") coerce into integer fields and repeated
+// arguments (eg "<file>...") into slice fields.  Options which docopt
+// reports as absent (nil) leave their fields untouched.
+func Docopt(to interface{}, opts map[string]interface{}) error {
+
+	pt := reflect.ValueOf(to)
+	if pt.Kind() != reflect.Ptr || pt.Elem().Kind() != reflect.Struct {
+		return errorf(ErrCannotSet, "expected *struct for 'to', got %v", pt.Kind())
+	}
+
+	m := make(map[string]interface{}, 2*len(opts))
+	add := func(key string, v interface{}) {
+		if prev, ok := m[key]; !ok || prev == nil {
+			m[key] = v
+		}
+	}
+	for k, v := range opts {
+		add(k, v)
+		if name := docoptName(k); name != k {
+			add(name, v)
+		}
+	}
+
+	// short options which docopt reports without a long name
+	for _, fl := range defaultDecoder.fields(pt.Elem().Type()) {
+		s, ok := fl.tag.option("short")
+		if !ok || len(s) != 1 || opts["-"+s] == nil {
+			continue
+		}
+		name := fl.tag.name
+		if name == "" || isPointer(name) {
+			name = strings.ToLower(wordBreaks(fl.Name, "-"))
+		}
+		add(name, opts["-"+s])
+	}
+
+	return Struct(to, m)
+}
+
+// docoptName strips the shape from docopt key k, giving eg "dry-run" for
+// "--dry-run", "file" for "<file>" or "FILE" and "v" for "-v"
+func docoptName(k string) string {
+	switch {
+	case strings.HasPrefix(k, "--") && len(k) > 2:
+		return k[2:]
+	case strings.HasPrefix(k, "-") && len(k) > 1 && k != "--":
+		return k[1:]
+	case strings.HasPrefix(k, "<") && strings.HasSuffix(k, ">"):
+		return k[1 : len(k)-1]
+	case k == strings.ToUpper(k) && k != strings.ToLower(k):
+		return strings.ToLower(k)
+	}
+	return k
+}
@@ -0,0 +1,73 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"testing"
+)
+
+func Test_Docopt(t *testing.T) {
+	// as returned by docopt for:
+	//   prog push [-v...] [-q] [--dry-run] [--depth=N] [--out=FILE] <file>... REMOTE
+	opts := map[string]interface{}{
+		"push":      true,
+		"-v":        2,
+		"-q":        true,
+		"--dry-run": true,
+		"--depth":   "3",
+		"--out":     nil,
+		"<file>":    []string{"a.txt", "b.txt"},
+		"REMOTE":    "origin",
+	}
+	var o struct {
+		Push    bool
+		Verbose int  `coerce:",short=v"`
+		Quiet   bool `coerce:",short=q"`
+		Dry     bool `coerce:"--dry-run"`
+		Depth   int
+		Out     string
+		File    []string
+		Remote  string
+	}
+	o.Out = "default"
+	err := Docopt(&o, opts)
+	report(err, true, o.Push, t)
+	report(err, 2, o.Verbose, t)
+	report(err, true, o.Quiet, t)
+	report(err, true, o.Dry, t)
+	report(err, 3, o.Depth, t)
+	report(err, "default", o.Out, t)
+	report(err, []string{"a.txt", "b.txt"}, o.File, t)
+	report(err, "origin", o.Remote, t)
+}
+
+func Test_docoptName(t *testing.T) {
+	for k, want := range map[string]string{
+		"--dry-run": "dry-run",
+		"-v":        "v",
+		"<file>":    "file",
+		"FILE":      "file",
+		"push":      "push",
+		"-":         "-",
+		"--":        "--",
+	} {
+		report(nil, want, docoptName(k), t)
+	}
+}
@@ -0,0 +1,110 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"strings"
+)
+
+// base64Encodings are tried in turn when decoding base64, so that both the
+// standard and URL alphabets are accepted, with or without padding
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// isByteArray reports whether t is a []byte or [N]byte
+func isByteArray(t reflect.Type) bool {
+	return isBytes(t) || t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8
+}
+
+// decodeBytes decodes s as per an `encoding=` tag: "base64" (standard or
+// URL alphabet, padding optional), "hex" (optionally prefixed "0x") or
+// "raw" (the bytes of s itself)
+func decodeBytes(s string, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "raw":
+		return []byte(s), nil
+	case "hex":
+		s = strings.TrimSpace(s)
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			s = s[2:]
+		}
+		return hex.DecodeString(s)
+	case "base64":
+		s = strings.TrimSpace(s)
+		var err error
+		for _, enc := range base64Encodings {
+			var b []byte
+			if b, err = enc.DecodeString(s); err == nil {
+				return b, nil
+			}
+		}
+		return nil, errorf(ErrInvalidValue, "invalid base64 %q: %w", s, err)
+	}
+	return nil, errorf(ErrUnsupportedConversion, "unknown encoding %q", encoding)
+}
+
+// encodeBytes is the reverse of decodeBytes, for Map; base64 uses the
+// standard alphabet
+func encodeBytes(b []byte, encoding string) interface{} {
+	switch strings.ToLower(encoding) {
+	case "raw":
+		return string(b)
+	case "hex":
+		return hex.EncodeToString(b)
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return b
+}
+
+// byteSlice returns the content of []byte or [N]byte v
+func byteSlice(v reflect.Value) []byte {
+	if v.Kind() == reflect.Array {
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b
+	}
+	return v.Bytes()
+}
+
+// unmarshallEncoded sets []byte or [N]byte vto from string s as per an
+// `encoding=` tag; arrays must be filled exactly
+func unmarshallEncoded(vto reflect.Value, s string, encoding string) error {
+	b, err := decodeBytes(s, encoding)
+	if err != nil {
+		return err
+	}
+	if vto.Kind() == reflect.Array {
+		if len(b) != vto.Len() {
+			return errorf(ErrUnsupportedConversion, "can't coerce %d bytes into %v", len(b), vto.Type())
+		}
+		reflect.Copy(vto, reflect.ValueOf(b))
+		return nil
+	}
+	vto.SetBytes(b)
+	return nil
+}
@@ -0,0 +1,59 @@
+package coerce
+
+import (
+	"testing"
+)
+
+func Test_Struct_encoding(t *testing.T) {
+	type x struct {
+		Key   []byte  `coerce:",encoding=base64"`
+		URL   []byte  `coerce:",encoding=base64"`
+		Hash  [4]byte `coerce:",encoding=hex"`
+		Salt  []byte  `coerce:",encoding=HEX"`
+		Raw   []byte  `coerce:",encoding=raw"`
+		Plain []byte
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"key":   "aGVsbG8=",
+		"url":   "-_8",
+		"hash":  "deadbeef",
+		"salt":  "0x0102",
+		"raw":   "hi",
+		"plain": "aGk=",
+	})
+	expected := x{
+		Key:   []byte("hello"),
+		URL:   []byte{0xfb, 0xff},
+		Hash:  [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Salt:  []byte{1, 2},
+		Raw:   []byte("hi"),
+		Plain: []byte("aGk="),
+	}
+	report(err, expected, myx, t)
+
+	for k, v := range map[string]string{"key": "not base64!", "hash": "dead", "salt": "xyz"} {
+		if err := Struct(&myx, map[string]interface{}{k: v}); err == nil {
+			t.Errorf("expected error for %s %q", k, v)
+		}
+	}
+
+	type y struct {
+		Data []byte `coerce:",encoding=base32"`
+	}
+	var myy y
+	if err := Struct(&myy, map[string]interface{}{"data": "ME======"}); err == nil {
+		t.Errorf("expected error for an unknown encoding")
+	}
+
+	// Map encodes them back
+	m, err := Map(x{Key: []byte("hello"), Hash: [4]byte{1, 2, 3, 4}, Raw: []byte("hi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report(nil, []interface{}{"aGVsbG8=", "01020304", "hi"}, []interface{}{m["Key"], m["Hash"], m["Raw"]}, t)
+	var back x
+	err = Struct(&back, m)
+	report(err, x{Key: []byte("hello"), URL: []byte{}, Hash: [4]byte{1, 2, 3, 4}, Salt: []byte{}, Raw: []byte("hi")}, back, t)
+}
@@ -0,0 +1,68 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"os"
+	"strings"
+)
+
+// Env coerces environment variables named with 'prefix' into the fields of
+// the structure pointed to by 'to', eg with prefix "APP" the variable
+// APP_MAX_RETRIES sets field MaxRetries.  The remainder of each name is
+// matched case-insensitively, with underscores standing for word breaks
+// as per Struct.  An empty prefix considers all variables.
+//
+// Fields of nested structs (and maps) are set by variables joining the
+// names of each level with underscores, as per NestedKeys, eg
+// APP_DB_POOL_MAX sets field DB.Pool.Max and APP_DB_MAX_CONNS field
+// DB.MaxConns.
+func Env(to interface{}, prefix string) error {
+	return defaultDecoder.Env(to, prefix)
+}
+
+// Env is Env with the Decoder's options; its NestedKeys separator, if
+// set, joins the names of nested fields in place of "_", eg "__" for
+// APP_DB__POOL__MAX.
+func (d *Decoder) Env(to interface{}, prefix string) error {
+	if d.nestSep == "" {
+		nested := *d
+		nested.nestSep = "_"
+		d = &nested
+	}
+	return d.Decode(to, envMap(os.Environ(), prefix))
+}
+
+// envMap returns the variables in 'environ' (of the form "NAME=value")
+// which start with prefix, keyed by the lowercased remainder of the name
+func envMap(environ []string, prefix string) map[string]interface{} {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	m := map[string]interface{}{}
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+		m[strings.ToLower(name[len(prefix):])] = value
+	}
+	return m
+}
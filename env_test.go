@@ -0,0 +1,82 @@
+package coerce
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Env(t *testing.T) {
+	type x struct {
+		MaxRetries int
+		Timeout    time.Duration
+		Debug      bool
+		Name       string
+	}
+
+	t.Setenv("COERCETEST_MAX_RETRIES", "5")
+	t.Setenv("COERCETEST_TIMEOUT", "2s")
+	t.Setenv("COERCETEST_DEBUG", "on")
+	t.Setenv("NAME", "ignored")
+
+	myx := x{Name: "default"}
+	err := Env(&myx, "COERCETEST")
+	report(err, x{5, 2 * time.Second, true, "default"}, myx, t)
+}
+
+func Test_Env_nested(t *testing.T) {
+	type pool struct {
+		Max     int
+		Timeout time.Duration
+	}
+	type x struct {
+		DB struct {
+			Host     string
+			MaxConns int
+			Pool     pool
+		}
+		Labels  map[string]string
+		Retries int
+	}
+
+	t.Setenv("COERCETEST_DB_HOST", "localhost")
+	t.Setenv("COERCETEST_DB_MAX_CONNS", "10")
+	t.Setenv("COERCETEST_DB_POOL_MAX", "4")
+	t.Setenv("COERCETEST_DB_POOL_TIMEOUT", "5s")
+	t.Setenv("COERCETEST_LABELS_TIER", "web")
+	t.Setenv("COERCETEST_RETRIES", "3")
+
+	var myx x
+	err := Env(&myx, "COERCETEST_")
+	var expected x
+	expected.DB.Host, expected.DB.MaxConns = "localhost", 10
+	expected.DB.Pool = pool{4, 5 * time.Second}
+	expected.Labels = map[string]string{"tier": "web"}
+	expected.Retries = 3
+	report(err, expected, myx, t)
+
+	t.Setenv("COERCETEST2_DB__POOL__MAX", "8")
+	t.Setenv("COERCETEST2_DB__MAX_CONNS", "20")
+	var myy x
+	err = NewDecoder(NestedKeys("__")).Env(&myy, "COERCETEST2")
+	var expected2 x
+	expected2.DB.MaxConns, expected2.DB.Pool.Max = 20, 8
+	report(err, expected2, myy, t)
+}
+
+func Test_Env_nested_prefix(t *testing.T) {
+	type x struct {
+		Log struct {
+			Level string
+		}
+		LogFile string
+	}
+
+	t.Setenv("COERCETEST3_LOG_LEVEL", "debug")
+	t.Setenv("COERCETEST3_LOG_FILE", "/var/log/app")
+
+	var myx x
+	err := NewDecoder(ErrorOnUnusedKeys()).Env(&myx, "COERCETEST3")
+	var expected x
+	expected.Log.Level, expected.LogFile = "debug", "/var/log/app"
+	report(err, expected, myx, t)
+}
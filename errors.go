@@ -0,0 +1,223 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The kinds of failure, which errors.Is finds in the errors returned by
+// this package (eg within a FieldError or DecodeError) so that callers
+// needn't match error text; the messages of the returned errors are more
+// specific
+var (
+	// ErrKeyNotFound is wrapped by the errors for missing keys, eg with
+	// ErrorOnMissingKeys or from Get
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrUnsupportedConversion is wrapped by the errors for values whose
+	// type can't be coerced into the target type at all
+	ErrUnsupportedConversion = errors.New("unsupported conversion")
+
+	// ErrOverflow is wrapped by the errors for numbers (and timestamps)
+	// outside the range of the target type
+	ErrOverflow = errors.New("overflow")
+
+	// ErrCannotSet is wrapped by the errors for fields which can't be set,
+	// eg unexported fields in a coerce_safe build
+	ErrCannotSet = errors.New("not settable")
+
+	// ErrInvalidValue is wrapped by the errors for values of a suitable
+	// type which don't parse, eg "abc" for an int or NaN where non-finite
+	// values are rejected
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// ErrRequired is wrapped by the FieldError for a field tagged
+// `coerce:",required"` which matched no key in the map
+var ErrRequired = errors.New("required")
+
+// kindError is an error of the kind given by one of the sentinels above,
+// with its own message
+type kindError struct {
+	kind error
+	err  error
+}
+
+// errorf returns an error of the given kind with a message formatted as
+// per fmt.Errorf (whose %w causes are also unwrapped)
+func errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind, fmt.Errorf(format, args...)}
+}
+
+// invalid marks err as ErrInvalidValue, unless it is nil or already of
+// one of the kinds above
+func invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, kind := range []error{ErrKeyNotFound, ErrUnsupportedConversion, ErrOverflow, ErrCannotSet, ErrInvalidValue} {
+		if errors.Is(err, kind) {
+			return err
+		}
+	}
+	return &kindError{ErrInvalidValue, err}
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the kind and the error, and so any causes it wraps
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// FieldError describes the failure to coerce one struct field
+type FieldError struct {
+	Field string       // name of the struct field
+	Path  string       // location of the field from the outermost struct, eg "Server.Listeners[2].Port"
+	Type  reflect.Type // type of the struct field
+	Key   string       // map key the value was taken from, if any
+	Value interface{}  // the value, or nil if redacted as secret
+	Err   error        // the cause
+
+	from     reflect.Type // type of the value, if any
+	redacted bool
+}
+
+// Error describes the failure, eg
+//
+//	field Server.Port (int) from key "port": can't coerce "http" (string): ...
+func (e *FieldError) Error() string {
+	if e.from == nil {
+		return fmt.Sprintf("field %s: %v", e.path(), e.Err)
+	}
+	shown := Redacted
+	if !e.redacted {
+		shown = render(e.Value)
+	}
+	key := ""
+	if e.Key != "" {
+		key = fmt.Sprintf(" from key %q", e.Key)
+	}
+	return fmt.Sprintf("field %s (%v)%s: can't coerce %s (%v): %v",
+		e.path(), e.Type, key, shown, e.from, e.Err)
+}
+
+// path returns Path, or Field if that is unset
+func (e *FieldError) path() string {
+	if e.Path == "" {
+		return e.Field
+	}
+	return e.Path
+}
+
+// Unwrap returns the cause
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned when one or more fields of a struct could not be
+// coerced.  Fields listed in Failed were left unchanged; those in Assigned
+// were set from the map; any others had no matching (non-nil) key.
+type DecodeError struct {
+	Assigned []string // fields successfully assigned, in struct order
+	Failed   []string // fields which could not be assigned, in struct order
+	Errors   []error  // the failures (mostly *FieldError), in struct field order
+}
+
+// Error lists the failures, one per line in struct field order
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	for i, err := range e.Errors {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the individual failures, so that errors.Is and errors.As
+// see each of them
+func (e *DecodeError) Unwrap() []error {
+	return e.Errors
+}
+
+// elemError locates a failure within a slice, array or map value
+type elemError struct {
+	index string // path segment, eg "[2]" or "[key]"
+	desc  string // eg "element 2" or "map key key"
+	err   error
+}
+
+func (e *elemError) Error() string {
+	return e.desc + ": " + e.err.Error()
+}
+
+// Unwrap returns the cause
+func (e *elemError) Unwrap() error {
+	return e.err
+}
+
+// locate prefixes the paths of any FieldErrors from nested structs in err,
+// the cause of the failure of the field at path, with that path and the
+// indexes of any elements on the way
+func locate(err error, path string) {
+	switch e := err.(type) {
+	case *elemError:
+		locate(e.err, path+e.index)
+	case *DecodeError:
+		for _, sub := range e.Errors {
+			relocate(sub, path+".")
+		}
+	case interface{ Unwrap() error }:
+		locate(e.Unwrap(), path)
+	}
+}
+
+// relocate prepends prefix to the paths of err, if it is a FieldError, and
+// of all the FieldErrors nested in it
+func relocate(err error, prefix string) {
+	fe, ok := err.(*FieldError)
+	if !ok {
+		return
+	}
+	fe.Path = prefix + fe.path()
+	for cause := fe.Err; cause != nil; {
+		switch e := cause.(type) {
+		case *elemError:
+			cause = e.err
+		case *DecodeError:
+			for _, sub := range e.Errors {
+				relocate(sub, prefix)
+			}
+			return
+		case interface{ Unwrap() error }:
+			cause = e.Unwrap()
+		default:
+			return
+		}
+	}
+}
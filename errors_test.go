@@ -0,0 +1,73 @@
+package coerce
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_sentinel_errors(t *testing.T) {
+	type x struct {
+		Host  string
+		Port  uint16
+		Token string `coerce:",required"`
+		Ch    chan int
+	}
+	var i8 int8
+	var u uint
+	var i int
+	var b bool
+	var f float64
+	var dur time.Duration
+	var tm time.Time
+	var ip net.IP
+	var m map[string]string
+	var myx x
+
+	tests := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"missing", NewDecoder(ErrorOnMissingKeys()).Decode(&myx, map[string]interface{}{"token": "t"}), ErrKeyNotFound},
+		{"required", Struct(&myx, map[string]interface{}{}), ErrRequired},
+		{"required key", Struct(&myx, map[string]interface{}{}), ErrKeyNotFound},
+		{"get", func() error { _, err := Get[int](map[string]interface{}{}, "n"); return err }(), ErrKeyNotFound},
+		{"overflow", Var(&i8, 300), ErrOverflow},
+		{"overflow string", Var(&i, "99999999999999999999"), ErrOverflow},
+		{"negative", Var(&u, -1), ErrOverflow},
+		{"field overflow", Struct(&myx, map[string]interface{}{"port": 70000, "token": "t"}), ErrOverflow},
+		{"unsupported", Struct(&myx, map[string]interface{}{"ch": "x", "token": "t"}), ErrUnsupportedConversion},
+		{"cannot set", Struct(myx, map[string]interface{}{}), ErrCannotSet},
+		{"invalid int", Var(&i, "notanint"), ErrInvalidValue},
+		{"invalid bool", Var(&b, "maybe"), ErrInvalidValue},
+		{"invalid duration", Var(&dur, "5 parsecs"), ErrInvalidValue},
+		{"invalid time", Var(&tm, "yesterday"), ErrInvalidValue},
+		{"invalid IP", Var(&ip, "300.1.1.1"), ErrInvalidValue},
+		{"NaN", NewDecoder(RejectNonFinite()).Var(&f, "NaN"), ErrInvalidValue},
+		{"invalid pair", Var(&m, "a=1,b"), ErrInvalidValue},
+		{"field invalid", Struct(&myx, map[string]interface{}{"port": "http", "token": "t"}), ErrInvalidValue},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.kind) {
+			t.Errorf("%s: expected error wrapping %v, got %v", tt.name, tt.kind, tt.err)
+		}
+	}
+
+	// parse failures are none of the other kinds
+	err := Var(&i, "abc")
+	for _, kind := range []error{ErrKeyNotFound, ErrUnsupportedConversion, ErrOverflow, ErrCannotSet} {
+		if errors.Is(err, kind) {
+			t.Errorf("expected %v not to wrap %v", err, kind)
+		}
+	}
+
+	// messages are unchanged by the kind
+	report(nil, "value 300 overflows int8 (range -128 to 127)", Var(&i8, 300).Error(), t)
+
+	var fe *FieldError
+	if err := Struct(&myx, map[string]interface{}{"ch": "x", "token": "t"}); !errors.As(err, &fe) || fe.Field != "Ch" {
+		t.Errorf("expected FieldError for Ch, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build !coerce_safe
+
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// exposeField uses an 'unsafe' workaround to make the unexported field vf
+// of an addressable struct settable (and readable via Interface)
+func exposeField(vf reflect.Value, f reflect.StructField) reflect.Value {
+	if string(f.Name[0]) == strings.ToLower(string(f.Name[0])) && vf.CanAddr() {
+		pu := unsafe.Pointer(vf.Addr().Pointer())
+		vf = reflect.Indirect(reflect.NewAt(vf.Type(), pu))
+	}
+	return vf
+}
@@ -0,0 +1,33 @@
+//go:build coerce_safe
+
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"reflect"
+)
+
+// exposeField leaves the unexported field vf as it is, since the coerce_safe
+// build tag rules out the 'unsafe' workaround; such fields are reported as
+// not settable
+func exposeField(vf reflect.Value, f reflect.StructField) reflect.Value {
+	return vf
+}
@@ -0,0 +1,31 @@
+//go:build coerce_safe
+
+// Tests for the coerce_safe build tag, under which unexported fields can't
+// be set; see expose_test.go
+
+package coerce
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Struct_unexported(t *testing.T) {
+	type x struct {
+		Name  string
+		cache string
+	}
+	var myx x
+	err := Struct(&myx, map[string]interface{}{"name": "n", "cache": "c"})
+	if !errors.Is(err, ErrCannotSet) {
+		t.Errorf("expected ErrCannotSet, got %v", err)
+	}
+	report(nil, x{"n", ""}, myx, t)
+
+	myx = x{}
+	err = NewDecoder(SkipUnexported(), ErrorOnUnusedKeys()).Decode(&myx, map[string]interface{}{"name": "n"})
+	report(err, x{"n", ""}, myx, t)
+
+	m, err := Map(x{"n", "c"})
+	report(err, map[string]interface{}{"Name": "n"}, m, t)
+}
@@ -0,0 +1,132 @@
+//go:build !coerce_safe
+
+// Tests which coerce or read unexported fields, which the coerce_safe build
+// tag rules out; see expose_safe_test.go
+
+package coerce
+
+import (
+	"log"
+	"testing"
+	"time"
+)
+
+func Test_Args(t *testing.T) {
+	type opts struct {
+		DryRun  bool
+		Verbose bool `coerce:",short=v"`
+		Name    string
+		Include []string `coerce:",short=I"`
+		Timeout time.Duration
+		n       int
+	}
+
+	var o opts
+	err := Args(&o, []string{
+		"--dry-run", "-v", "--name=widget", "-Iinc1", "-I", "inc2",
+		"--include", "inc3", "--timeout", "5s", "-n", "3",
+	})
+
+	expected := opts{true, true, "widget", []string{"inc1", "inc2", "inc3"}, 5 * time.Second, 3}
+	report(err, expected, o, t)
+
+	o = opts{}
+	err = Args(&o, []string{"-vn5", "--verbose=false"})
+	report(err, opts{Verbose: false, n: 5}, o, t)
+
+	for _, bad := range [][]string{{"--nope"}, {"--name"}, {"stray"}, {"-x"}} {
+		if err := Args(&o, bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func Test_Struct(t *testing.T) {
+	log.SetFlags(log.Flags() | log.Lshortfile)
+
+	type x struct {
+		intslice foo
+		Boolval  bool
+		s        string
+		notthere int
+		MapDown  string
+	}
+
+	mymap := map[string]interface{}{
+		"--intslice": []string{"5", "-12", "0.5k"},
+		"--Boolval":  true,
+		"-s":         nil,
+		"--map-down": "now implemented",
+	}
+
+	var myx x
+	myx.s = "hello"
+
+	expected := x{
+		intslice: []int{5, -12, 512},
+		Boolval:  true,
+		s:        "hello",
+		notthere: 0,
+		MapDown:  "now implemented",
+	}
+
+	log.Printf("%#v", mymap)
+	err := Struct(&myx, mymap, "--%s", "-%s")
+
+	report(err, expected, myx, t)
+}
+
+func Test_Struct_squash(t *testing.T) {
+	type db struct {
+		Host string
+		Port int
+	}
+	type x struct {
+		Common
+		*logging
+		time.Time
+		DB   db     `coerce:",squash"`
+		Name string // hides Common.Name
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"--name":    "outer",
+		"--verbose": "yes",
+		"--level":   "debug",
+		"--host":    "db1",
+		"--port":    "5432",
+		"--time":    "2024-01-02",
+	}, "--%s")
+
+	expected := x{
+		Common:  Common{Verbose: true},
+		logging: &logging{Level: "debug"},
+		Time:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		DB:      db{"db1", 5432},
+		Name:    "outer",
+	}
+	report(err, expected, myx, t)
+
+	myx = x{}
+	err = Struct(&myx, map[string]interface{}{"verbose": "true"})
+	report(err, x{Common: Common{Verbose: true}}, myx, t)
+
+	fields, err := Fields(myx)
+	report(err, []KeyValue{
+		{"Verbose", true}, {"Time", time.Time{}}, {"Host", ""}, {"Port", 0}, {"Name", ""},
+	}, fields, t)
+}
+
+func Test_Struct_unexported(t *testing.T) {
+	type x struct {
+		Name  string
+		cache string
+	}
+	var myx x
+	err := Struct(&myx, map[string]interface{}{"name": "n", "cache": "c"})
+	report(err, x{"n", "c"}, myx, t)
+
+	m, err := Map(myx)
+	report(err, map[string]interface{}{"Name": "n", "cache": "c"}, m, t)
+}
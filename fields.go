@@ -0,0 +1,167 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// field is a struct field to be decoded, possibly promoted from a squashed
+// (eg embedded) struct
+type field struct {
+	reflect.StructField
+	tag   fieldTag
+	index []int // as for reflect.Value.FieldByIndex
+	depth int   // levels of squashing
+}
+
+type fieldsKey struct {
+	t              reflect.Type
+	tagName        string
+	fallback       string // TagFallback names, comma-separated
+	skipUnexported bool
+	converters     [2]uint64 // as per convertersVersion
+}
+
+// fieldCache holds the results of fields, by struct type, tag names and the
+// converters in force, which decide whether embedded structs are squashed
+var fieldCache sync.Map
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// fields lists the fields of struct type t to decode, in struct order,
+// with the fields of squashed structs flattened in their place.  As with
+// Go's own promotion, a shallower field hides deeper ones of the same
+// name.  Fields tagged "-" are omitted.  The result is shared and must not
+// be modified.
+func (d *Decoder) fields(t reflect.Type) []field {
+	key := fieldsKey{t, d.tagName, strings.Join(d.tagFallback, ","), d.skipUnexported, d.convertersVersion()}
+	if list, ok := fieldCache.Load(key); ok {
+		return list.([]field)
+	}
+
+	all := d.appendFields(nil, t, nil, 0, map[reflect.Type]bool{t: true})
+
+	// keep the shallowest field of each name
+	shallowest := map[string]int{}
+	for _, f := range all {
+		if depth, ok := shallowest[f.Name]; !ok || f.depth < depth {
+			shallowest[f.Name] = f.depth
+		}
+	}
+	list := make([]field, 0, len(all))
+	for _, f := range all {
+		if f.depth == shallowest[f.Name] {
+			list = append(list, f)
+			shallowest[f.Name] = -1 // first one only
+		}
+	}
+
+	fieldCache.Store(key, list)
+	return list
+}
+
+// appendFields appends the fields of struct type t, found at index path
+// 'parent', to list
+func (d *Decoder) appendFields(list []field, t reflect.Type, parent []int, depth int, seen map[reflect.Type]bool) []field {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := d.parseTag(f)
+		if tag.skip {
+			continue
+		}
+		index := append(append([]int(nil), parent...), i)
+		if st, ok := d.squashed(f, tag); ok && !seen[st] {
+			seen[st] = true
+			list = d.appendFields(list, st, index, depth+1, seen)
+			delete(seen, st)
+			continue
+		}
+		if d.skipUnexported && !f.IsExported() && !hasSetter(t, f) {
+			continue
+		}
+		list = append(list, field{f, tag, index, depth})
+	}
+	return list
+}
+
+// squashed returns the struct type whose fields are to be treated as those
+// of the parent, if f is tagged `coerce:",squash"` or is an untagged
+// embedded struct (or pointer to struct).  Embedded types like time.Time
+// which coerce parses as a whole are not squashed unless tagged.
+func (d *Decoder) squashed(f reflect.StructField, tag fieldTag) (reflect.Type, bool) {
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if _, ok := tag.option("squash"); ok {
+		return t, true
+	}
+	if !f.Anonymous || tag.name != "" {
+		return nil, false
+	}
+	if d.parsedWhole(t) {
+		return nil, false
+	}
+	return t, true
+}
+
+// parsedWhole reports whether coerce parses values of struct type t as a
+// whole (eg time.Time, or types with a registered converter) rather than
+// field by field
+func parsedWhole(t reflect.Type) bool {
+	_, parsed := stringParsers[t]
+	_, converted := lookupConverter(t)
+	return parsed || converted || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// parsedWhole is parsedWhole, also consulting the Decoder's own converters
+func (d *Decoder) parsedWhole(t reflect.Type) bool {
+	_, own := d.converters[t]
+	return own || parsedWhole(t)
+}
+
+// fieldByIndex returns the (settable) field of struct v at index,
+// allocating any nil squashed struct pointers on the way if alloc is set
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		f := v.Type().Field(x)
+		v = v.Field(x)
+		if !v.CanSet() {
+			v = exposeField(v, f)
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,89 @@
+package coerce
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type Common struct {
+	Name    string
+	Verbose bool
+}
+
+type logging struct {
+	Level string
+}
+
+func Test_fields_skip(t *testing.T) {
+	type x struct {
+		Name    string
+		Cache   map[string]int `coerce:"-"`
+		Dash    string         `coerce:"-,"`
+		private int            `coerce:"-"`
+	}
+	myx := x{Cache: map[string]int{"k": 1}, private: 3}
+	err := Struct(&myx, map[string]interface{}{
+		"name": "n", "cache": map[string]int{"other": 2}, "Cache": nil, "-": "dash", "private": 4,
+	})
+	report(err, x{"n", map[string]int{"k": 1}, "dash", 3}, myx, t)
+
+	m, err := Map(myx)
+	report(err, map[string]interface{}{"Name": "n", "-": "dash"}, m, t)
+
+	if err := Args(&myx, []string{"--cache=x"}); err == nil {
+		t.Error("expected unknown option error for skipped field")
+	}
+}
+
+func Test_Decoder_SkipUnexported(t *testing.T) {
+	type common struct {
+		Verbose bool
+	}
+	type x struct {
+		common
+		Name  string
+		cache string
+	}
+	var myx x
+	err := NewDecoder(SkipUnexported(), ErrorOnUnusedKeys()).Decode(&myx, map[string]interface{}{
+		"name": "n", "verbose": true,
+	})
+	report(err, x{common{true}, "n", ""}, myx, t)
+
+	err = NewDecoder(SkipUnexported()).Decode(&myx, map[string]interface{}{"cache": "c"})
+	report(err, "", myx.cache, t)
+}
+
+type account struct {
+	name    string
+	balance int
+	Owner   string
+}
+
+func (a *account) SetName(name string) {
+	a.name = strings.ToUpper(name)
+}
+
+func (a *account) SetBalance(b int) error {
+	if b < 0 {
+		return errors.New("negative balance")
+	}
+	a.balance = b
+	return nil
+}
+
+func Test_Struct_setters(t *testing.T) {
+	var a account
+	for _, d := range []*Decoder{defaultDecoder, NewDecoder(SkipUnexported())} {
+		a = account{}
+		err := d.Decode(&a, map[string]interface{}{"name": "alice", "balance": "1k", "owner": "bob"})
+		report(err, account{"ALICE", 1024, "bob"}, a, t)
+	}
+
+	err := Struct(&a, map[string]interface{}{"balance": -5})
+	if err == nil || !strings.Contains(err.Error(), "negative balance") {
+		t.Errorf("expected setter error, got %v", err)
+	}
+	report(nil, 1024, a.balance, t)
+}
@@ -0,0 +1,184 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Flags coerces the flags which were set on fs (as per fs.Visit) into the
+// fields of the structure pointed to by 'to', matching flag names (eg
+// "dry-run") to field names (eg DryRun) as per Struct
+func Flags(to interface{}, fs *flag.FlagSet) error {
+	m := map[string]interface{}{}
+	fs.Visit(func(f *flag.Flag) {
+		if g, ok := f.Value.(flag.Getter); ok {
+			m[f.Name] = g.Get()
+		} else {
+			m[f.Name] = f.Value.String()
+		}
+	})
+	return Struct(to, m)
+}
+
+// RegisterFlags defines a flag on fs for each field of the structure
+// pointed to by 'to', which fs.Parse then sets directly, using coerce to
+// parse the values.  Flags are named by the lowercased, hyphenated field
+// name (eg "dry-run" for DryRun) or by the field's tag; tags may also give
+// a single-letter alias and usage text, eg
+//
+//	Verbose bool     `coerce:",short=v,usage=more output"`
+//	Include []string `coerce:"include,short=I"`
+//
+// The fields' current values are the defaults.  Bool fields need no
+// value; slice fields collect repeated flags.  Positional fields, and
+// fields such as nested structs which can't be parsed from one string,
+// are skipped.  It is an error for two flags to have the same name.
+func RegisterFlags(fs *flag.FlagSet, to interface{}) error {
+	pt := reflect.ValueOf(to)
+	if pt.Kind() != reflect.Ptr || pt.Elem().Kind() != reflect.Struct {
+		return errorf(ErrCannotSet, "expected *struct for 'to', got %v", pt.Kind())
+	}
+	vt := pt.Elem()
+
+	for _, fl := range defaultDecoder.fields(vt.Type()) {
+		if _, pos := fl.tag.option("pos"); pos || !flaggable(fl.Type) {
+			continue
+		}
+		name := strings.TrimLeft(fl.tag.name, "-")
+		if name == "" || isPointer(fl.tag.name) {
+			name = strings.ToLower(wordBreaks(fl.Name, "-"))
+		}
+		usage, _ := fl.tag.option("usage")
+
+		vf, _ := fieldByIndex(vt, fl.index, true)
+		if !vf.CanSet() {
+			continue
+		}
+		names := []string{name}
+		for _, alias := range fl.tag.aliases {
+			names = append(names, strings.TrimLeft(alias, "-"))
+		}
+		if s, ok := fl.tag.option("short"); ok && len(s) == 1 {
+			names = append(names, s)
+		}
+		ff := &fieldFlag{v: vf}
+		for _, n := range names {
+			// fs.Var panics on redefinition
+			if fs.Lookup(n) != nil {
+				return fmt.Errorf("field %s: flag -%s is already defined", fl.Name, n)
+			}
+			fs.Var(ff, n, usage)
+		}
+	}
+	return nil
+}
+
+// flaggable reports whether values of type t can be given as flags
+func flaggable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		return parsedWhole(t)
+	case reflect.Ptr:
+		return flaggable(t.Elem())
+	case reflect.Slice:
+		return isBytes(t) || parsedWhole(t) || flaggable(t.Elem())
+	case reflect.Map:
+		// as "key=value" pairs
+		return flaggable(t.Key()) && flaggable(t.Elem())
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+// fieldFlag is a flag.Value which sets a struct field
+type fieldFlag struct {
+	v   reflect.Value
+	set bool // whether Set has been called, for lists
+}
+
+// String returns the field's value
+func (f *fieldFlag) String() string {
+	if f == nil || !f.v.IsValid() {
+		return ""
+	}
+	if f.repeats() {
+		parts := make([]string, f.v.Len())
+		for i := range parts {
+			parts[i] = String(f.v.Index(i).Interface())
+		}
+		return strings.Join(parts, ",")
+	}
+	return String(f.v.Interface())
+}
+
+// Set coerces s into the field; repeated flags append to slice fields,
+// and add the "key=value" pairs they give to map fields, replacing the
+// default on the first
+func (f *fieldFlag) Set(s string) error {
+	if f.v.Kind() == reflect.Map {
+		pairs := reflect.New(f.v.Type()).Elem()
+		if err := defaultDecoder.unmarshall(pairs, reflect.ValueOf(s)); err != nil {
+			return err
+		}
+		if !f.set || f.v.IsNil() {
+			f.v.Set(reflect.MakeMap(f.v.Type()))
+		}
+		for iter := pairs.MapRange(); iter.Next(); {
+			f.v.SetMapIndex(iter.Key(), iter.Value())
+		}
+		f.set = true
+		return nil
+	}
+	if f.repeats() {
+		elem := reflect.New(f.v.Type().Elem()).Elem()
+		if err := defaultDecoder.unmarshall(elem, reflect.ValueOf(s)); err != nil {
+			return err
+		}
+		if !f.set {
+			f.v.Set(reflect.MakeSlice(f.v.Type(), 0, 1))
+		}
+		f.v.Set(reflect.Append(f.v, elem))
+		f.set = true
+		return nil
+	}
+	f.set = true
+	return defaultDecoder.unmarshall(f.v, reflect.ValueOf(s))
+}
+
+// repeats reports whether the field collects repeated flags, rather than
+// being parsed whole like []net.IP
+func (f *fieldFlag) repeats() bool {
+	return isList(f.v.Type()) && !parsedWhole(f.v.Type())
+}
+
+// Get returns the field's value, as per flag.Getter
+func (f *fieldFlag) Get() interface{} {
+	return f.v.Interface()
+}
+
+// IsBoolFlag lets bool flags be given without a value
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.v.Kind() == reflect.Bool
+}
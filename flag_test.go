@@ -0,0 +1,111 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"flag"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Flags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("max-count", 1, "")
+	fs.String("name", "", "")
+	fs.Bool("dry-run", false, "")
+	fs.Duration("timeout", 0, "")
+	if err := fs.Parse([]string{"-max-count=5", "-dry-run", "-timeout", "2s"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var s struct {
+		MaxCount int
+		Name     string
+		DryRun   bool
+		Timeout  time.Duration
+	}
+	s.Name = "default"
+	err := Flags(&s, fs)
+	report(err, 5, s.MaxCount, t)
+	report(err, "default", s.Name, t) // not visited
+	report(err, true, s.DryRun, t)
+	report(err, 2*time.Second, s.Timeout, t)
+}
+
+func Test_RegisterFlags(t *testing.T) {
+	var s struct {
+		MaxCount int
+		Verbose  bool     `coerce:",short=v,usage=more output"`
+		Include  []string `coerce:"include,short=I"`
+		Timeout  time.Duration
+		Addr     net.IP
+		Hosts    []net.IP
+		Nested   struct{ A int }
+		File     string `coerce:",pos"`
+	}
+	s.MaxCount = 3
+	s.Include = []string{"default"}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	err := RegisterFlags(fs, &s)
+	report(err, nil, err, t)
+
+	report(nil, "3", fs.Lookup("max-count").DefValue, t)
+	report(nil, "more output", fs.Lookup("v").Usage, t)
+	report(nil, true, fs.Lookup("nested") == nil && fs.Lookup("file") == nil, t)
+
+	err = fs.Parse([]string{"-v", "-I", "a", "--include=b", "-timeout=1m",
+		"-addr", "10.0.0.1", "-hosts", "10.0.0.2,10.0.0.3", "rest"})
+	report(err, 3, s.MaxCount, t)
+	report(err, true, s.Verbose, t)
+	report(err, []string{"a", "b"}, s.Include, t)
+	report(err, time.Minute, s.Timeout, t)
+	report(err, "10.0.0.1", s.Addr.String(), t)
+	report(err, 2, len(s.Hosts), t)
+	report(err, []string{"rest"}, fs.Args(), t)
+
+	if err := fs.Parse([]string{"-max-count=x"}); err == nil {
+		t.Error("expected error for bad int flag")
+	}
+	if err := RegisterFlags(fs, s); err == nil {
+		t.Error("expected error for non-pointer target")
+	}
+}
+
+func Test_RegisterFlags_duplicate(t *testing.T) {
+	var short struct {
+		Verbose bool `coerce:",short=v"`
+		V       bool
+	}
+	var alias struct {
+		Host string
+		Addr string `coerce:",alias=host"`
+	}
+	for _, to := range []interface{}{&short, &alias} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := RegisterFlags(fs, to); err == nil || !strings.Contains(err.Error(), "already defined") {
+			t.Errorf("expected error for duplicate flag, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// expandGlobs coerces vfrom (a string or list of strings) into vto after
+// expanding any glob patterns it contains.  Patterns without matches are
+// kept literally.
+func (d *Decoder) expandGlobs(vto reflect.Value, vfrom reflect.Value) error {
+	var patterns []string
+	if vfrom.Kind() == reflect.String {
+		patterns = []string{vfrom.String()}
+	} else if err := d.Var(&patterns, vfrom.Interface()); err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, p := range patterns {
+		if !strings.ContainsAny(p, "*?[") {
+			paths = append(paths, p)
+			continue
+		}
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			matches = []string{p}
+		}
+		paths = append(paths, matches...)
+	}
+	return d.unmarshall(vto, reflect.ValueOf(paths))
+}
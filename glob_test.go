@@ -0,0 +1,36 @@
+package coerce
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Struct_glob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.gz", "b.gz", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type x struct {
+		Logs  []string `coerce:",glob"`
+		Plain []string
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"logs":  []string{filepath.Join(dir, "*.gz"), filepath.Join(dir, "*.none"), "plain"},
+		"plain": []string{filepath.Join(dir, "*.gz")},
+	})
+
+	expected := x{
+		Logs:  []string{filepath.Join(dir, "a.gz"), filepath.Join(dir, "b.gz"), filepath.Join(dir, "*.none"), "plain"},
+		Plain: []string{filepath.Join(dir, "*.gz")},
+	}
+	report(err, expected, myx, t)
+
+	err = Struct(&myx, map[string]interface{}{"logs": filepath.Join(dir, "*.txt")})
+	report(err, []string{filepath.Join(dir, "c.txt")}, myx.Logs, t)
+}
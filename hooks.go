@@ -0,0 +1,78 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"reflect"
+)
+
+// DecodeHook transforms a value of type 'from' destined for a target of
+// type 'to' before coerce's own conversions are tried, returning the
+// value to use in its place (which may be of any type), or nil to leave
+// the target untouched.  Hooks which don't apply should return value
+// unchanged.
+type DecodeHook func(from reflect.Type, to reflect.Type, value interface{}) (interface{}, error)
+
+// DecodeHooks adds hooks to those applied, in order, to each value the
+// Decoder coerces, including the elements of slices and maps and the
+// fields of nested structs, eg
+//
+//	expand := func(from, to reflect.Type, v interface{}) (interface{}, error) {
+//		if s, ok := v.(string); ok {
+//			return os.ExpandEnv(s), nil
+//		}
+//		return v, nil
+//	}
+//	d := coerce.NewDecoder(coerce.DecodeHooks(expand))
+//
+// Hooks run before registered converters and tag options such as layout=
+// and sep=; the output of each is the input of the next.  Fields tagged
+// `coerce:",raw"` bypass them.
+func DecodeHooks(hooks ...DecodeHook) Option {
+	return func(d *Decoder) {
+		d.hooks = append(d.hooks, hooks...)
+	}
+}
+
+// applyHooks passes vfrom through the Decoder's hooks, returning the
+// final value, or the zero Value if a hook returned nil
+func (d *Decoder) applyHooks(tto reflect.Type, vfrom reflect.Value) (reflect.Value, error) {
+	if vfrom.Kind() == reflect.Interface {
+		if vfrom.IsNil() {
+			return vfrom, nil
+		}
+		vfrom = vfrom.Elem()
+	}
+	if !vfrom.CanInterface() {
+		return vfrom, nil
+	}
+
+	v := vfrom.Interface()
+	for _, hook := range d.hooks {
+		var err error
+		if v, err = hook(reflect.TypeOf(v), tto, v); err != nil {
+			return reflect.Value{}, err
+		}
+		if v == nil {
+			return reflect.Value{}, nil
+		}
+	}
+	return reflect.ValueOf(v), nil
+}
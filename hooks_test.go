@@ -0,0 +1,118 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_DecodeHooks(t *testing.T) {
+	var seen []string
+	record := func(from, to reflect.Type, v interface{}) (interface{}, error) {
+		seen = append(seen, from.String()+"->"+to.String())
+		return v, nil
+	}
+	// minutes given as bare numbers
+	minutes := func(from, to reflect.Type, v interface{}) (interface{}, error) {
+		if to == reflect.TypeOf(time.Duration(0)) && from.Kind() == reflect.Float64 {
+			return v.(float64) * float64(time.Minute), nil
+		}
+		return v, nil
+	}
+	upper := func(from, to reflect.Type, v interface{}) (interface{}, error) {
+		if s, ok := v.(string); ok {
+			return strings.ToUpper(s), nil
+		}
+		return v, nil
+	}
+	skip := func(from, to reflect.Type, v interface{}) (interface{}, error) {
+		if v == "skip" {
+			return nil, nil
+		}
+		return v, nil
+	}
+
+	var o struct {
+		Timeout time.Duration
+		Names   []string
+		Ptr     *string
+		Keep    string
+		Raw     interface{} `coerce:",raw"`
+	}
+	o.Keep = "kept"
+	d := NewDecoder(DecodeHooks(skip, record), DecodeHooks(minutes, upper))
+	err := d.Decode(&o, map[string]interface{}{
+		"timeout": 1.5,
+		"names":   []interface{}{"a", "b"},
+		"ptr":     "p",
+		"keep":    "skip",
+		"raw":     "raw",
+	})
+	report(err, 90*time.Second, o.Timeout, t)
+	report(err, []string{"A", "B"}, o.Names, t)
+	report(err, true, o.Ptr != nil && *o.Ptr == "P", t)
+	report(err, "kept", o.Keep, t)
+	report(err, "raw", o.Raw, t)
+	report(err, []string{
+		"float64->time.Duration",
+		"[]interface {}->[]string", "string->string", "string->string",
+		"string->*string",
+	}, seen, t)
+
+	boom := errors.New("boom")
+	fail := func(from, to reflect.Type, v interface{}) (interface{}, error) {
+		return nil, boom
+	}
+	err = NewDecoder(DecodeHooks(fail)).Decode(&o, map[string]interface{}{"keep": "x"})
+	if !errors.Is(err, boom) {
+		t.Errorf("expected hook error, got %v", err)
+	}
+}
+
+func Test_DecodeHooks_tagged(t *testing.T) {
+	vars := map[string]string{"DAY": "2024-03-01", "KEY": "68690a", "HOSTS": "a;b"}
+	expand := func(from, to reflect.Type, v interface{}) (interface{}, error) {
+		if s, ok := v.(string); ok && strings.HasPrefix(s, "$") {
+			return vars[s[1:]], nil
+		}
+		return v, nil
+	}
+
+	var o struct {
+		Day   time.Time `coerce:",layout=2006-01-02"`
+		Key   []byte    `coerce:",encoding=hex"`
+		Hosts []string  `coerce:",sep=;"`
+		Plain string
+	}
+	err := NewDecoder(DecodeHooks(expand)).Decode(&o, map[string]interface{}{
+		"day":   "$DAY",
+		"key":   "$KEY",
+		"hosts": "$HOSTS",
+		"plain": "$DAY",
+	})
+	report(err, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), o.Day, t)
+	report(err, []byte("hi\n"), o.Key, t)
+	report(err, []string{"a", "b"}, o.Hosts, t)
+	report(err, "2024-03-01", o.Plain, t)
+}
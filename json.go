@@ -0,0 +1,101 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// JSON decodes the JSON object read from r and coerces it into the fields
+// of the structure pointed to by 'to', as per Struct, so that the likes of
+// "512M" or "30s" can be given for numeric fields, which encoding/json
+// would reject.  Numbers are decoded as json.Number so that large integers
+// keep their precision; interface{} fields receive them as such.
+func JSON(to interface{}, r io.Reader, formats ...string) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return fmt.Errorf("decoding JSON: %w", err)
+	}
+	return Struct(to, m, formats...)
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// unmarshallJSON passes s to the UnmarshalJSON method of vto, if it has
+// one, as a last resort for strings coerce can't parse itself: as-is if s
+// is valid JSON, and otherwise (or should that fail) quoted as a JSON
+// string.  It reports whether vto has the method, and any error from it.
+func unmarshallJSON(vto reflect.Value, s string) (bool, error) {
+	if !vto.CanAddr() {
+		return false, nil
+	}
+	u, ok := vto.Addr().Interface().(json.Unmarshaler)
+	if !ok {
+		return false, nil
+	}
+	var err error
+	if raw := []byte(s); json.Valid(raw) {
+		if err = u.UnmarshalJSON(raw); err == nil {
+			return true, nil
+		}
+	}
+	quoted, _ := json.Marshal(s)
+	if qerr := u.UnmarshalJSON(quoted); qerr == nil || err == nil {
+		err = qerr
+	}
+	if err != nil {
+		return true, invalid(fmt.Errorf("%v.UnmarshalJSON: %w", vto.Type(), err))
+	}
+	return true, nil
+}
+
+// decodeJSON decodes vfrom into a generic value if it is a json.RawMessage
+// (or a []byte holding valid JSON) and tto is a struct, map or non-byte
+// slice, so that partially-decoded payloads can be coerced as usual
+func decodeJSON(vfrom reflect.Value, tto reflect.Type) (interface{}, bool) {
+	switch tto.Kind() {
+	case reflect.Struct, reflect.Map:
+	case reflect.Slice:
+		if isBytes(tto) {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	if !isBytes(vfrom.Type()) {
+		return nil, false
+	}
+	raw := vfrom.Bytes()
+	if vfrom.Type() != rawMessageType && !json.Valid(raw) {
+		return nil, false
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
@@ -0,0 +1,116 @@
+package coerce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Struct_json_raw(t *testing.T) {
+	type server struct {
+		Host string
+		Port int
+	}
+	type x struct {
+		Server server
+		Limits map[string]int
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"server": json.RawMessage(`{"host": "example.com", "port": "8080"}`),
+		"limits": json.RawMessage(`{"conns": "1k"}`),
+	})
+
+	expected := x{
+		Server: server{"example.com", 8080},
+		Limits: map[string]int{"conns": 1024},
+	}
+	report(err, expected, myx, t)
+}
+
+func Test_JSON(t *testing.T) {
+	type server struct {
+		Host string
+		Port uint16
+	}
+	var o struct {
+		Cache   int
+		Timeout time.Duration
+		ID      uint64
+		Server  server
+		Tags    []string
+	}
+	err := JSON(&o, strings.NewReader(`{
+		"cache": "512M",
+		"timeout": "30s",
+		"id": 18446744073709551615,
+		"server": {"host": "example.com", "port": 8080},
+		"tags": ["a", "b"]
+	}`))
+	report(err, 512<<20, o.Cache, t)
+	report(err, 30*time.Second, o.Timeout, t)
+	report(err, uint64(18446744073709551615), o.ID, t)
+	report(err, server{"example.com", 8080}, o.Server, t)
+	report(err, []string{"a", "b"}, o.Tags, t)
+
+	if err := JSON(&o, strings.NewReader(`[1, 2]`)); err == nil {
+		t.Error("expected error for non-object JSON")
+	}
+}
+
+// severity only unmarshals from JSON, by name or number
+type severity int
+
+func (l *severity) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return json.Unmarshal(b, (*int)(l))
+	}
+	for i, n := range []string{"debug", "info", "warn"} {
+		if n == name {
+			*l = severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", name)
+}
+
+// vec only unmarshals from a JSON [x, y] array
+type vec struct{ X, Y int }
+
+func (v *vec) UnmarshalJSON(b []byte) error {
+	var xy [2]int
+	if err := json.Unmarshal(b, &xy); err != nil {
+		return err
+	}
+	v.X, v.Y = xy[0], xy[1]
+	return nil
+}
+
+func Test_Struct_json_Unmarshaler(t *testing.T) {
+	type x struct {
+		Level  severity
+		Other  severity
+		Origin vec
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"level":  "warn",  // quoted for UnmarshalJSON
+		"other":  "1",     // parsed as an int directly
+		"origin": "[3,4]", // valid JSON as-is
+	})
+	report(err, x{Level: 2, Other: 1, Origin: vec{3, 4}}, myx, t)
+
+	err = Struct(&myx, map[string]interface{}{"level": "loud"})
+	if err == nil || !strings.Contains(err.Error(), `unknown severity "loud"`) {
+		t.Errorf("expected UnmarshalJSON's own error, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+// Package language teaches coerce to parse BCP 47 language tags such as
+// "en-US" into golang.org/x/text/language.Tag fields.  It is kept in its
+// own package so that coerce itself does not depend on golang.org/x/text;
+// import it for its side effects:
+//
+//	import _ "github.com/SeeSpotRun/coerce/language"
+package language
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/SeeSpotRun/coerce"
+	xlanguage "golang.org/x/text/language"
+)
+
+func init() {
+	coerce.RegisterConverter(reflect.TypeOf(xlanguage.Tag{}), Convert)
+}
+
+// Convert parses a string or []byte holding a BCP 47 language tag into a
+// language.Tag
+func Convert(from interface{}) (interface{}, error) {
+	switch v := from.(type) {
+	case string:
+		return xlanguage.Parse(v)
+	case []byte:
+		return xlanguage.Parse(string(v))
+	case fmt.Stringer:
+		return xlanguage.Parse(v.String())
+	}
+	return nil, fmt.Errorf("can't coerce %T to language.Tag", from)
+}
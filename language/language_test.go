@@ -0,0 +1,49 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/SeeSpotRun/coerce"
+	xlanguage "golang.org/x/text/language"
+)
+
+func Test_Convert(t *testing.T) {
+	for from, expected := range map[interface{}]string{
+		"en-US":      "en-US",
+		"en-us":      "en-US",
+		"zh-Hant-TW": "zh-Hant-TW",
+		"fr":         "fr",
+	} {
+		tag, err := Convert(from)
+		if err != nil || tag.(xlanguage.Tag).String() != expected {
+			t.Errorf("Convert(%q): expected %s, got %v (%v)", from, expected, tag, err)
+		}
+	}
+	for _, from := range []interface{}{"", "x", "not a tag", "abcdefghi", 42} {
+		if _, err := Convert(from); err == nil {
+			t.Errorf("Convert(%#v): expected error", from)
+		}
+	}
+}
+
+func Test_Struct_language(t *testing.T) {
+	type prefs struct {
+		Lang     xlanguage.Tag
+		Fallback xlanguage.Tag
+	}
+
+	var p prefs
+	err := coerce.Struct(&p, map[string]interface{}{
+		"lang":     "fr-CA",
+		"fallback": []byte("en"),
+	})
+
+	expected := prefs{xlanguage.MustParse("fr-CA"), xlanguage.MustParse("en")}
+	if err != nil || p != expected {
+		t.Errorf("expected %v, got %v (%v)", expected, p, err)
+	}
+
+	if err := coerce.Struct(&p, map[string]interface{}{"lang": "not a tag"}); err == nil {
+		t.Errorf("expected error for invalid tag")
+	}
+}
@@ -0,0 +1,135 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Limits bounds the size of the source values a Decoder will accept, so
+// that untrusted input (eg request bodies) can be coerced safely.  Zero
+// fields are unlimited.
+type Limits struct {
+	MaxSliceLen  int // elements in any slice or array
+	MaxMapSize   int // entries in any map (including the top-level map)
+	MaxStringLen int // bytes in any string
+	MaxDepth     int // nesting of maps and slices, the top level being 1
+}
+
+// SizeLimits makes the Decoder check sources against l before coercing
+// anything, failing with a descriptive error if a limit is exceeded.
+// MaxSliceLen and MaxMapSize also bound the lists and maps produced from
+// strings, eg by splitting on the Separator or expanding IP ranges.
+func SizeLimits(l Limits) Option {
+	return func(d *Decoder) {
+		d.limits = l
+	}
+}
+
+// checkLimits walks v, returning an error naming the location of the
+// first value found to exceed the Decoder's limits
+func (d *Decoder) checkLimits(v interface{}) error {
+	if d.limits == (Limits{}) {
+		return nil
+	}
+	return d.limits.check(reflect.ValueOf(v), "source", 0)
+}
+
+func (l Limits) check(v reflect.Value, path string, depth int) error {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return l.check(v.Elem(), path, depth)
+
+	case reflect.String:
+		if l.MaxStringLen > 0 && v.Len() > l.MaxStringLen {
+			return fmt.Errorf("%s: string length %d exceeds limit of %d", path, v.Len(), l.MaxStringLen)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// raw bytes are limited like strings
+			if l.MaxStringLen > 0 && v.Len() > l.MaxStringLen {
+				return fmt.Errorf("%s: byte length %d exceeds limit of %d", path, v.Len(), l.MaxStringLen)
+			}
+			return nil
+		}
+		if err := l.checkDepth(depth+1, path); err != nil {
+			return err
+		}
+		if l.MaxSliceLen > 0 && v.Len() > l.MaxSliceLen {
+			return fmt.Errorf("%s: length %d exceeds limit of %d", path, v.Len(), l.MaxSliceLen)
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := l.check(v.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if err := l.checkDepth(depth+1, path); err != nil {
+			return err
+		}
+		if l.MaxMapSize > 0 && v.Len() > l.MaxMapSize {
+			return fmt.Errorf("%s: map size %d exceeds limit of %d", path, v.Len(), l.MaxMapSize)
+		}
+		for _, k := range sortedKeys(v) {
+			p := fmt.Sprintf("%s[%q]", path, fmt.Sprint(k))
+			if err := l.check(k, p, depth+1); err != nil {
+				return err
+			}
+			if err := l.check(v.MapIndex(k), p, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkSliceLen checks the length n of a list produced while decoding, eg
+// by splitting a string, against MaxSliceLen
+func (l Limits) checkSliceLen(n int) error {
+	if l.MaxSliceLen > 0 && n > l.MaxSliceLen {
+		return fmt.Errorf("list of %d elements exceeds limit of %d", n, l.MaxSliceLen)
+	}
+	return nil
+}
+
+// checkMapSize is checkSliceLen for maps, eg of "key=value" pairs
+func (l Limits) checkMapSize(n int) error {
+	if l.MaxMapSize > 0 && n > l.MaxMapSize {
+		return fmt.Errorf("map of %d entries exceeds limit of %d", n, l.MaxMapSize)
+	}
+	return nil
+}
+
+func (l Limits) checkDepth(depth int, path string) error {
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return fmt.Errorf("%s: nesting depth exceeds limit of %d", path, l.MaxDepth)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package coerce
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+)
+
+func Test_Decoder_SizeLimits(t *testing.T) {
+	type x struct {
+		Name string
+		Tags []string
+		Meta map[string]interface{}
+	}
+
+	d := NewDecoder(SizeLimits(Limits{MaxSliceLen: 2, MaxMapSize: 3, MaxStringLen: 8, MaxDepth: 3}))
+
+	var myx x
+	err := d.Decode(&myx, map[string]interface{}{"name": "ok", "tags": []string{"a", "b"}})
+	report(err, x{Name: "ok", Tags: []string{"a", "b"}}, myx, t)
+
+	for _, c := range []struct {
+		d        *Decoder
+		from     map[string]interface{}
+		expected string
+	}{
+		{d, map[string]interface{}{"name": "much too long"}, `source["name"]: string length 13`},
+		{d, map[string]interface{}{"tags": []string{"a", "b", "c"}}, `source["tags"]: length 3`},
+		{d, map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4}, `source: map size 4`},
+		{d, map[string]interface{}{"meta": json.RawMessage(`{"a": "long"}`)}, `source["meta"]: byte length`},
+		{d, map[string]interface{}{"meta": map[string]interface{}{
+			"a": map[string]interface{}{"b": []int{1}},
+		}}, `source["meta"]["a"]["b"]: nesting`},
+		{
+			NewDecoder(SizeLimits(Limits{MaxMapSize: 3})),
+			map[string]interface{}{"meta": json.RawMessage(`{"a": 1, "b": 2, "c": 3, "d": 4}`)},
+			`source: map size 4`,
+		},
+	} {
+		err := c.d.Decode(&myx, c.from)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("expected error containing %s, got %v", c.expected, err)
+		}
+	}
+}
+
+func Test_Decoder_SizeLimits_expanded(t *testing.T) {
+	type x struct {
+		Hosts  []net.IP
+		Tags   []string
+		Nets   []net.IPNet
+		Labels map[string]string
+		Lists  []string `coerce:",sep=;"`
+	}
+
+	d := NewDecoder(IPRanges(), SizeLimits(Limits{MaxStringLen: 1024, MaxSliceLen: 100, MaxMapSize: 2}))
+	for _, c := range []struct {
+		from     map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"hosts": "10.0.0.0-10.0.255.255"}, "beyond 100 addresses"},
+		{map[string]interface{}{"hosts": strings.Repeat("10.0.0.1,", 101)}, "IP list exceeds 100"},
+		{map[string]interface{}{"tags": strings.Repeat("a,", 100) + "a"}, "list of 101 elements"},
+		{map[string]interface{}{"lists": strings.Repeat("a;", 100) + "a"}, "list of 101 elements"},
+		{map[string]interface{}{"nets": strings.Repeat("1.0.0.0/8,", 101)}, "list of 101 elements"},
+		{map[string]interface{}{"labels": "a=1,b=2,c=3"}, "map of 3 entries"},
+		{map[string]interface{}{"labels": []string{"a=1", "b=2", "c=3"}}, "map of 3 entries"},
+	} {
+		var myx x
+		err := d.Decode(&myx, c.from)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("expected error containing %s, got %v", c.expected, err)
+		}
+	}
+
+	// violations are reported in key order
+	lim := NewDecoder(SizeLimits(Limits{MaxStringLen: 1}))
+	for i := 0; i < 10; i++ {
+		var myx x
+		err := lim.Decode(&myx, map[string]interface{}{"tags": "ab", "hosts": "cd", "labels": "ef"})
+		if err == nil || !strings.Contains(err.Error(), `source["hosts"]`) {
+			t.Fatalf("expected error for hosts, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Map is the reverse of Struct: it returns a map holding the values of the
+// fields (exported or not) of the structure 'from' (or pointed to by
+// 'from').  Keys are the field names formatted by the first of 'formats',
+// if any, eg "--%s" will map field "foo" to key "--foo", unless the field
+// has a tag naming its key.
+//
+// time.Time fields are emitted as-is unless tagged with a layout, eg
+//
+//	Created time.Time `coerce:",layout=RFC3339"`
+//	Expires time.Time `coerce:",layout=unix"`
+//	Day     time.Time `coerce:",layout=2006-01-02"`
+//
+// where the layout may name a time package constant, be one of "unix",
+// "unixmilli" or "unixnano" for integer epochs, or be a custom layout.
+// Likewise []byte fields tagged with an encoding, eg `coerce:",encoding=hex"`,
+// are emitted as encoded strings.
+//
+// Fields tagged `coerce:",secret"` are emitted as Redacted, and the
+// entries of `coerce:",remain"` maps as keys in their own right.  Nested
+// structs (and pointers to and slices of them) are emitted as nested maps,
+// with keys formatted by "%s", so that the result can be passed back to
+// Struct.
+//
+// Use Fields instead where the output order matters.
+func Map(from interface{}, formats ...string) (map[string]interface{}, error) {
+	fields, err := Fields(from, formats...)
+	if err != nil {
+		return nil, err
+	}
+	return kvMap(fields), nil
+}
+
+// kvMap collects fields into a map
+func kvMap(fields []KeyValue) map[string]interface{} {
+	m := make(map[string]interface{}, len(fields))
+	for _, kv := range fields {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+// KeyValue is a single key and value emitted by Fields
+type KeyValue struct {
+	Key   string
+	Value interface{}
+}
+
+// Fields is like Map but returns the keys and values as a list in struct
+// field order, for stable output
+func Fields(from interface{}, formats ...string) ([]KeyValue, error) {
+	format := "%s"
+	if len(formats) > 0 {
+		format = formats[0]
+	}
+	visiting := map[visit]bool{}
+	if v := reflect.ValueOf(from); v.Kind() == reflect.Ptr && !v.IsNil() {
+		visiting[visit{v.Pointer(), v.Type()}] = true
+	}
+	return fieldsOf(from, format, visiting)
+}
+
+// visit identifies a pointer or slice being emitted by Fields
+type visit struct {
+	p uintptr
+	t reflect.Type
+}
+
+// fieldsOf does the work for Fields.  visiting holds the struct pointers
+// (and slices of structs) being emitted further up, so that cycles end in the pointer itself
+// rather than recursing forever.
+func fieldsOf(from interface{}, format string, visiting map[visit]bool) ([]KeyValue, error) {
+
+	vs := reflect.Indirect(reflect.ValueOf(from))
+	if vs.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct or *struct for 'from', got %T", from)
+	}
+	if !vs.CanAddr() {
+		// take an addressable copy so that unexported fields can be read
+		cp := reflect.New(vs.Type()).Elem()
+		cp.Set(vs)
+		vs = cp
+	}
+
+	fields := make([]KeyValue, 0, vs.NumField())
+	for _, fl := range defaultDecoder.fields(vs.Type()) {
+		f, tag := fl.StructField, fl.tag
+		vf, ok := fieldByIndex(vs, fl.index, false)
+		if !ok || !vf.CanInterface() {
+			// in a nil squashed struct, or unexported in a coerce_safe build
+			continue
+		}
+
+		if _, ok := tag.option("remain"); ok && vf.Kind() == reflect.Map && vf.Type().Key().Kind() == reflect.String {
+			// emit the collected keys in place of the field
+			for _, k := range sortedKeys(vf) {
+				v, err := mapValue(vf.MapIndex(k), visiting)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, KeyValue{k.String(), v})
+			}
+			continue
+		}
+
+		v := vf.Interface()
+		if _, secret := tag.option("secret"); secret {
+			v = Redacted
+		} else if t, ok := v.(time.Time); ok {
+			layout, _ := tag.option("layout")
+			v = formatTime(t, layout)
+		} else if enc, ok := tag.option("encoding"); ok && isByteArray(vf.Type()) {
+			v = encodeBytes(byteSlice(vf), enc)
+		} else {
+			var err error
+			if v, err = mapValue(vf, visiting); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+
+		key := tag.name
+		if key == "" || isPointer(key) {
+			key = fmt.Sprintf(format, f.Name)
+		}
+		fields = append(fields, KeyValue{key, v})
+	}
+	return fields, nil
+}
+
+// mapValue returns the value of v, with any plain structs (bar those
+// parsed as a whole, such as time.Time) it holds directly, by pointer or in
+// a slice converted to maps as per Map.  Pointers and slices already in
+// visiting are returned as they are.
+func mapValue(v reflect.Value, visiting map[visit]bool) (interface{}, error) {
+	plain := func(t reflect.Type) bool {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		return t.Kind() == reflect.Struct && !parsedWhole(t)
+	}
+
+	switch {
+	case v.Kind() == reflect.Slice && !v.IsNil() && plain(v.Type().Elem()):
+		key := visit{v.Pointer(), v.Type()}
+		if visiting[key] {
+			return v.Interface(), nil
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			var err error
+			if list[i], err = mapValue(v.Index(i), visiting); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case v.Kind() == reflect.Ptr && !v.IsNil() && plain(v.Type()):
+		key := visit{v.Pointer(), v.Type()}
+		if visiting[key] {
+			return v.Interface(), nil
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+		fields, err := fieldsOf(v.Interface(), "%s", visiting)
+		if err != nil {
+			return nil, err
+		}
+		return kvMap(fields), nil
+	case v.Kind() == reflect.Struct && plain(v.Type()):
+		fields, err := fieldsOf(v.Interface(), "%s", visiting)
+		if err != nil {
+			return nil, err
+		}
+		return kvMap(fields), nil
+	}
+	return v.Interface(), nil
+}
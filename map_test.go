@@ -0,0 +1,110 @@
+//go:build !coerce_safe
+
+package coerce
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Map_time_layout(t *testing.T) {
+	tm := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	type x struct {
+		Plain   time.Time
+		Created time.Time `coerce:",layout=RFC3339"`
+		Expires time.Time `coerce:",layout=unix"`
+		day     time.Time `coerce:",layout=2006-01-02"`
+	}
+
+	m, err := Map(x{tm, tm, tm, tm}, "--%s")
+
+	expected := map[string]interface{}{
+		"--Plain":   tm,
+		"--Created": "2024-01-02T15:04:05Z",
+		"--Expires": int64(1704207845),
+		"--day":     "2024-01-02",
+	}
+	report(err, expected, m, t)
+}
+
+func Test_Fields_order(t *testing.T) {
+	type x struct {
+		Zeta  int
+		Alpha string
+		mid   bool
+	}
+
+	fields, err := Fields(&x{1, "a", true}, "--%s")
+
+	expected := []KeyValue{{"--Zeta", 1}, {"--Alpha", "a"}, {"--mid", true}}
+	report(err, expected, fields, t)
+}
+
+func Test_Map_round_trip(t *testing.T) {
+	type server struct {
+		Host string
+		Port int
+	}
+	type x struct {
+		Name    string `coerce:"app-name"`
+		Main    server
+		Backup  *server
+		Mirrors []server
+		Started time.Time
+		secret  string
+	}
+
+	orig := x{
+		Name:    "app",
+		Main:    server{"a", 1},
+		Backup:  &server{"b", 2},
+		Mirrors: []server{{"c", 3}},
+		Started: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		secret:  "s",
+	}
+
+	m, err := Map(orig, "--%s")
+	report(err, map[string]interface{}{
+		"app-name":  "app",
+		"--Main":    map[string]interface{}{"Host": "a", "Port": 1},
+		"--Backup":  map[string]interface{}{"Host": "b", "Port": 2},
+		"--Mirrors": []interface{}{map[string]interface{}{"Host": "c", "Port": 3}},
+		"--Started": orig.Started,
+		"--secret":  "s",
+	}, m, t)
+
+	var back x
+	err = Struct(&back, m, "--%s")
+	report(err, orig, back, t)
+}
+
+type mapNode struct {
+	Name string
+	Next *mapNode
+	Kids []mapNode
+}
+
+func Test_Map_cycle(t *testing.T) {
+	a := &mapNode{Name: "a"}
+	a.Next = a
+	m, err := Map(a)
+	report(err, map[string]interface{}{"Name": "a", "Next": a, "Kids": []mapNode(nil)}, m, t)
+
+	b := &mapNode{Name: "b", Next: &mapNode{Name: "c"}}
+	b.Next.Next = b
+	m, err = Map(b)
+	report(err, map[string]interface{}{
+		"Name": "b",
+		"Next": map[string]interface{}{"Name": "c", "Next": b, "Kids": []mapNode(nil)},
+		"Kids": []mapNode(nil),
+	}, m, t)
+
+	kids := make([]mapNode, 1)
+	kids[0].Kids = kids
+	m, err = Map(mapNode{Kids: kids})
+	report(err, map[string]interface{}{
+		"Name": "", "Next": (*mapNode)(nil),
+		"Kids": []interface{}{map[string]interface{}{"Name": "", "Next": (*mapNode)(nil), "Kids": kids}},
+	}, m, t)
+}
@@ -0,0 +1,44 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+// Metadata records what a decode actually did, so that callers such as
+// configuration loaders can tell applied settings from defaults
+type Metadata struct {
+	SetFields       []string // fields assigned from the map, in struct order
+	MissingFields   []string // fields with no matching key, in struct order
+	DefaultedFields []string // fields assigned their `default=`, in struct order
+	UnusedKeys      []string // map keys which matched no field, sorted
+}
+
+// StructWithMetadata is like Struct but also returns Metadata describing
+// which fields were set and which keys went unused
+func StructWithMetadata(to interface{}, from map[string]interface{}, formats ...string) (Metadata, error) {
+	return NewDecoder(Formats(formats...)).DecodeMetadata(to, from)
+}
+
+// DecodeMetadata is like Decode but also returns Metadata describing the
+// top-level struct.  Failed fields appear in none of the lists; see
+// DecodeError.
+func (d *Decoder) DecodeMetadata(to interface{}, from map[string]interface{}) (Metadata, error) {
+	var md Metadata
+	err := d.decode(to, from, &md, nil)
+	return md, err
+}
@@ -0,0 +1,36 @@
+package coerce
+
+import "testing"
+
+func Test_StructWithMetadata(t *testing.T) {
+	type x struct {
+		Host    string
+		Port    int
+		Verbose bool
+		Bad     int
+		Retries int    `coerce:",default=3"`
+		Mode    string `coerce:",default=fast"`
+	}
+
+	myx := x{Host: "localhost"}
+	md, err := StructWithMetadata(&myx, map[string]interface{}{
+		"--port":  "8080",
+		"--bad":   "x",
+		"--debug": "true",
+		"--extra": 1,
+		"--mode":  nil,
+	}, "--%s")
+	if _, ok := err.(*DecodeError); !ok {
+		t.Errorf("expected *DecodeError, got %v", err)
+	}
+
+	expected := Metadata{
+		SetFields:       []string{"Port"},
+		MissingFields:   []string{"Host", "Verbose", "Retries"},
+		DefaultedFields: []string{"Retries", "Mode"},
+		UnusedKeys:      []string{"--debug", "--extra"},
+	}
+	report(nil, expected, md, t)
+	report(nil, 3, myx.Retries, t)
+	report(nil, "fast", myx.Mode, t)
+}
@@ -0,0 +1,82 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import "fmt"
+
+// Migration renames the source key From to To before the source is matched
+// against the target's fields, converting the value by Convert if that is
+// set.  An empty To drops the key.
+type Migration struct {
+	From    string
+	To      string
+	Convert Converter // optional
+}
+
+// Migrate makes the Decoder apply migrations, in order, to the top-level
+// keys of each source it decodes, eg
+//
+//	d := NewDecoder(Formats("--%s"), Migrate(
+//		Migration{From: "--user", To: "--username"},
+//		Migration{From: "--timeout-ms", To: "--timeout", Convert: msToDuration},
+//	))
+//
+// so that configuration schema changes can be absorbed in one place.  If
+// the source already holds key To, the old key is dropped in its favour.
+// Each migration applied is reported as a Warning.
+func Migrate(migrations ...Migration) Option {
+	return func(d *Decoder) {
+		d.migrations = append(d.migrations, migrations...)
+	}
+}
+
+// migrate returns from with the Decoder's migrations applied; from itself
+// is left unchanged
+func (d *Decoder) migrate(from map[string]interface{}) (map[string]interface{}, error) {
+	copied := false
+	for _, m := range d.migrations {
+		v, ok := from[m.From]
+		if !ok {
+			continue
+		}
+		if !copied {
+			cp := make(map[string]interface{}, len(from))
+			for k, v := range from {
+				cp[k] = v
+			}
+			from, copied = cp, true
+		}
+		delete(from, m.From)
+
+		if _, exists := from[m.To]; exists || m.To == "" {
+			d.warn(Warning{Key: m.From, Message: fmt.Sprintf("key %q ignored", m.From)})
+			continue
+		}
+		if m.Convert != nil {
+			var err error
+			if v, err = m.Convert(v); err != nil {
+				return nil, fmt.Errorf("migrating key %q to %q: %v", m.From, m.To, err)
+			}
+		}
+		from[m.To] = v
+		d.warn(Warning{Key: m.From, Message: fmt.Sprintf("key %q migrated to %q", m.From, m.To)})
+	}
+	return from, nil
+}
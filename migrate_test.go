@@ -0,0 +1,53 @@
+package coerce
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_Decoder_Migrate(t *testing.T) {
+	type x struct {
+		Username string
+		Timeout  time.Duration
+		Verbose  bool
+	}
+
+	msToDuration := func(from interface{}) (interface{}, error) {
+		ms, err := Int(from)
+		return time.Duration(ms) * time.Millisecond, err
+	}
+
+	from := map[string]interface{}{
+		"--user":       "alice",
+		"--timeout-ms": "1500",
+		"--debug":      "true",
+		"--verbose":    "false",
+	}
+
+	var warnings []string
+	var myx x
+	err := NewDecoder(Formats("--%s"), Migrate(
+		Migration{From: "--user", To: "--username"},
+		Migration{From: "--timeout-ms", To: "--timeout", Convert: msToDuration},
+		Migration{From: "--debug", To: "--verbose"},
+	), Warnings(func(w Warning) {
+		warnings = append(warnings, w.Message)
+	})).Decode(&myx, from)
+
+	report(err, x{"alice", 1500 * time.Millisecond, false}, myx, t)
+	report(nil, []string{
+		`key "--user" migrated to "--username"`,
+		`key "--timeout-ms" migrated to "--timeout"`,
+		`key "--debug" ignored`,
+	}, warnings, t)
+	if _, ok := from["--user"]; !ok {
+		t.Errorf("source map was modified: %v", from)
+	}
+
+	bad := func(interface{}) (interface{}, error) { return nil, fmt.Errorf("nope") }
+	err = NewDecoder(Migrate(Migration{From: "a", To: "b", Convert: bad})).Decode(&myx, map[string]interface{}{"a": 1})
+	if err == nil {
+		t.Errorf("expected migration error")
+	}
+}
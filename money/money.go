@@ -0,0 +1,165 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+// Package money provides a Money type and registers a coerce converter
+// which parses strings such as "$1,234.56", "1234.56 EUR" or "EUR -5" into
+// it.  Import it for its side effects if only the registration is wanted:
+//
+//	import _ "github.com/SeeSpotRun/coerce/money"
+package money
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/SeeSpotRun/coerce"
+	"github.com/SeeSpotRun/coerce/decimal"
+)
+
+func init() {
+	coerce.RegisterConverter(reflect.TypeOf(Money{}), Convert)
+}
+
+// Money is an amount in the minor units of a currency, eg
+// Money{123456, "USD"} is $1,234.56
+type Money struct {
+	Amount   int64  // in minor units (cents, pence, etc)
+	Currency string // ISO 4217 code, or "" if unspecified
+}
+
+// symbols maps currency symbols to ISO 4217 codes
+var symbols = map[string]string{
+	"$": "USD",
+	"€": "EUR",
+	"£": "GBP",
+	"¥": "JPY",
+	"₹": "INR",
+	"₩": "KRW",
+	"₽": "RUB",
+	"₺": "TRY",
+}
+
+// minorDigits holds the number of minor-unit digits for currencies which
+// don't have the usual 2
+var minorDigits = map[string]int{
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0, "UGX": 0,
+}
+
+// MinorDigits returns the number of digits after the point in amounts of
+// currency code
+func MinorDigits(code string) int {
+	if n, ok := minorDigits[code]; ok {
+		return n
+	}
+	return 2
+}
+
+// Parse parses an amount with an optional currency symbol (prefix) or ISO
+// 4217 code (prefix or suffix); thousands separators are ignored.  It is
+// an error for the amount to be more precise than the currency's minor
+// unit.
+func Parse(s string) (Money, error) {
+	t := strings.TrimSpace(s)
+	sign := ""
+	if strings.HasPrefix(t, "-") || strings.HasPrefix(t, "+") {
+		sign, t = t[:1], strings.TrimSpace(t[1:])
+	}
+
+	code := ""
+	for sym, c := range symbols {
+		if strings.HasPrefix(t, sym) {
+			code, t = c, strings.TrimSpace(t[len(sym):])
+			break
+		}
+	}
+	if code == "" {
+		if c, rest, ok := cutCode(t, true); ok {
+			code, t = c, rest
+		} else if c, rest, ok := cutCode(t, false); ok {
+			code, t = c, rest
+		}
+	}
+	if sign == "" && (strings.HasPrefix(t, "-") || strings.HasPrefix(t, "+")) {
+		sign, t = t[:1], strings.TrimSpace(t[1:])
+	}
+
+	d, err := decimal.Parse(sign + strings.Replace(t, ",", "", -1))
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid money amount %q", s)
+	}
+
+	digits := MinorDigits(code)
+	if d.Scale > digits {
+		return Money{}, fmt.Errorf("money amount %q has more than %d decimal places", s, digits)
+	}
+	amount := d.Unscaled
+	for i := d.Scale; i < digits; i++ {
+		if amount > math.MaxInt64/10 || amount < math.MinInt64/10 {
+			return Money{}, fmt.Errorf("money amount %q out of range", s)
+		}
+		amount *= 10
+	}
+	return Money{Amount: amount, Currency: code}, nil
+}
+
+// cutCode splits a 3-letter currency code from the start (or end) of s
+func cutCode(s string, prefix bool) (string, string, bool) {
+	if len(s) < 3 {
+		return "", s, false
+	}
+	code, rest := s[:3], s[3:]
+	if !prefix {
+		code, rest = s[len(s)-3:], s[:len(s)-3]
+	}
+	for _, r := range code {
+		if !unicode.IsUpper(r) {
+			return "", s, false
+		}
+	}
+	return code, strings.TrimSpace(rest), true
+}
+
+// String formats m as eg "1234.56 USD"
+func (m Money) String() string {
+	s := decimal.Decimal{Unscaled: m.Amount, Scale: MinorDigits(m.Currency)}.String()
+	if m.Currency != "" {
+		s += " " + m.Currency
+	}
+	return s
+}
+
+// Convert coerces strings (and anything else printable) into a Money
+func Convert(from interface{}) (interface{}, error) {
+	switch v := from.(type) {
+	case Money:
+		return v, nil
+	case string:
+		return Parse(v)
+	case []byte:
+		return Parse(string(v))
+	case float32, float64:
+		return Parse(strconv.FormatFloat(reflect.ValueOf(v).Float(), 'f', -1, 64))
+	}
+	return Parse(fmt.Sprint(from))
+}
@@ -0,0 +1,46 @@
+package money
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SeeSpotRun/coerce"
+)
+
+func Test_Parse(t *testing.T) {
+	for s, expected := range map[string]Money{
+		"$1,234.56":   {123456, "USD"},
+		"1234.56 EUR": {123456, "EUR"},
+		"EUR -5":      {-500, "EUR"},
+		"-£0.5":       {-50, "GBP"},
+		"¥1,000":      {1000, "JPY"},
+		"1.234 KWD":   {1234, "KWD"},
+		"12.3":        {1230, ""},
+	} {
+		m, err := Parse(s)
+		if err != nil || m != expected {
+			t.Errorf("Parse(%q): expected %v, got %v (%v)", s, expected, m, err)
+		}
+	}
+	for _, s := range []string{"", "$", "¥1.5", "1.234 USD", "12 usd",
+		"100000000000000000 USD", "-100000000000000000 USD"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q): expected error", s)
+		}
+	}
+}
+
+func Test_Struct_money(t *testing.T) {
+	type row struct {
+		Price Money
+	}
+
+	var r row
+	err := coerce.Struct(&r, map[string]interface{}{"price": "$19.99"})
+	if err != nil || !reflect.DeepEqual(row{Money{1999, "USD"}}, r) {
+		t.Errorf("unexpected %v (%v)", r, err)
+	}
+	if s := r.Price.String(); s != "19.99 USD" {
+		t.Errorf("expected 19.99 USD, got %s", s)
+	}
+}
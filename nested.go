@@ -0,0 +1,93 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"reflect"
+	"strings"
+)
+
+// nestable reports whether fields of type t can be filled from flat keys
+// as per NestedKeys: plain structs, maps with string keys and pointers to
+// them
+func (d *Decoder) nestable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !d.parsedWhole(t) ||
+		t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
+// nestedKeys gathers the keys of 'from' which join one of prefixes (tried
+// in order) and a remainder with the Decoder's NestedKeys separator, into
+// a map keyed by the remainders.  Keys in 'claimed', which belong to
+// sibling fields, are left out.  It returns the prefix matched, the map
+// and the keys gathered, if any.
+func (d *Decoder) nestedKeys(from map[string]interface{}, prefixes []string, claimed map[string]bool) (string, map[string]interface{}, []string) {
+	for _, prefix := range prefixes {
+		p := prefix + d.nestSep
+		var sub map[string]interface{}
+		var flat []string
+		for k, v := range from {
+			if len(k) > len(p) && strings.HasPrefix(k, p) && !claimed[k] {
+				if sub == nil {
+					sub = map[string]interface{}{}
+				}
+				sub[k[len(p):]] = v
+				flat = append(flat, k)
+			}
+		}
+		if len(flat) > 0 {
+			return prefix, sub, flat
+		}
+	}
+	return "", nil, nil
+}
+
+// siblingKeys returns the keys the fields other than fields[skip] match
+// directly, so that eg with separator "_" the key "log_file" is left to
+// field LogFile rather than gathered for field Log.  keys and formats are
+// as for decodeStruct.
+func (d *Decoder) siblingKeys(fields []field, skip int, formats []string, keys [][]string) map[string]bool {
+	claimed := map[string]bool{}
+	for j, fl := range fields {
+		if j == skip {
+			continue
+		}
+		var names []string
+		switch {
+		case fl.tag.name != "":
+			names = []string{fl.tag.name}
+		case keys != nil:
+			names = keys[j]
+		case d.keyNames != nil:
+			names = d.keyNames(fl.Name)
+		default:
+			names = candidateKeys(fl.Name, formats)
+		}
+		for _, k := range names {
+			claimed[k] = true
+		}
+		for _, k := range fl.tag.aliases {
+			claimed[k] = true
+		}
+	}
+	return claimed
+}
@@ -0,0 +1,54 @@
+package coerce
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_Decoder_NestedKeys(t *testing.T) {
+	type pool struct {
+		Max     int
+		Timeout time.Duration
+	}
+	type db struct {
+		Host     string
+		MaxConns int
+		Pool     *pool
+	}
+	type x struct {
+		DB      db
+		Cache   db `coerce:"cache"`
+		Labels  map[string]string
+		Name    string
+		Ignored db
+	}
+
+	d := NewDecoder(NestedKeys("."), ErrorOnUnusedKeys())
+	var myx x
+	err := d.Decode(&myx, map[string]interface{}{
+		"db.host":         "localhost",
+		"db.max-conns":    "10",
+		"db.pool.max":     "4",
+		"db.pool.timeout": "5s",
+		"cache.host":      "redis",
+		"labels.env":      "prod",
+		"name":            "app",
+		"ignored":         map[string]interface{}{"host": "direct"},
+		"ignored.host":    "flat",
+	})
+	expected := x{
+		DB:      db{Host: "localhost", MaxConns: 10, Pool: &pool{4, 5 * time.Second}},
+		Cache:   db{Host: "redis"},
+		Labels:  map[string]string{"env": "prod"},
+		Name:    "app",
+		Ignored: db{Host: "direct"},
+	}
+	report(nil, `unused keys ["ignored.host"]`, fmt.Sprint(err), t)
+	report(nil, expected, myx, t)
+
+	// without the option flat keys are just keys
+	var plain x
+	err = Struct(&plain, map[string]interface{}{"db.host": "localhost"})
+	report(err, x{}, plain, t)
+}
@@ -0,0 +1,284 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxIPs limits the number of addresses the ranges in a []net.IP source
+// may expand to, across the whole list
+const maxIPs = 1 << 16
+
+var ipSliceType = reflect.TypeOf([]net.IP{})
+
+// HostPort is a validated network address of the form "host:port", as
+// used for listen and dial addresses.  Host may be empty (all interfaces),
+// a hostname, or an IPv4 or IPv6 literal.
+type HostPort struct {
+	Host string
+	Port int
+}
+
+// String formats hp as "host:port", bracketing IPv6 hosts
+func (hp HostPort) String() string {
+	return net.JoinHostPort(hp.Host, strconv.Itoa(hp.Port))
+}
+
+// parseHostPort parses and validates strings like "0.0.0.0:8080",
+// "localhost:80" and "[::1]:53"
+func parseHostPort(s string) (HostPort, error) {
+	host, portStr, err := net.SplitHostPort(strings.TrimSpace(s))
+	if err != nil {
+		return HostPort{}, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return HostPort{}, errorf(ErrInvalidValue, "invalid port %q in address %q", portStr, s)
+	}
+	if host != "" && net.ParseIP(stripZone(host)) == nil && !validHostname(host) {
+		return HostPort{}, errorf(ErrInvalidValue, "invalid host %q in address %q", host, s)
+	}
+	return HostPort{Host: host, Port: int(port)}, nil
+}
+
+// parseIPPort parses a "host:port" string whose host is empty or an IP
+// literal (with optional IPv6 zone), as needed for net.TCPAddr and
+// net.UDPAddr; hostnames are rejected rather than resolved
+func parseIPPort(s string) (net.IP, int, string, error) {
+	hp, err := parseHostPort(s)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if hp.Host == "" {
+		return nil, hp.Port, "", nil
+	}
+	var zone string
+	host := hp.Host
+	if i := strings.LastIndex(host, "%"); i >= 0 {
+		host, zone = host[:i], host[i+1:]
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, 0, "", errorf(ErrInvalidValue, "address %q: host must be an IP literal", s)
+	}
+	return ip, hp.Port, zone, nil
+}
+
+// stripZone removes any IPv6 "%zone" suffix from host
+func stripZone(host string) string {
+	if i := strings.LastIndex(host, "%"); i >= 0 {
+		return host[:i]
+	}
+	return host
+}
+
+// validHostname checks host against the RFC 1123 hostname syntax
+func validHostname(host string) bool {
+	if len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseIP parses a single IPv4 or IPv6 address
+func parseIP(s string) (net.IP, error) {
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return nil, errorf(ErrInvalidValue, "invalid IP address %q", s)
+	}
+	return ip, nil
+}
+
+// parseIPNet parses a CIDR string such as "10.0.0.0/8" into the network it
+// describes.  A bare address is taken as a single-host network.
+func parseIPNet(s string) (net.IPNet, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip, err := parseIP(s)
+		if err != nil {
+			return net.IPNet{}, errorf(ErrInvalidValue, "invalid CIDR address %q", s)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return net.IPNet{}, err
+	}
+	return *n, nil
+}
+
+// parsePrefix parses a CIDR string into a netip.Prefix.  As for
+// parseIPNet, a bare address is taken as a single-host prefix.
+func parsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		a, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, errorf(ErrInvalidValue, "invalid CIDR address %q", s)
+		}
+		return a.Prefix(a.BitLen())
+	}
+	return netip.ParsePrefix(s)
+}
+
+// parseIPNetList parses a comma-separated list of CIDR strings
+func parseIPNetList(s string) ([]net.IPNet, error) {
+	var nets []net.IPNet
+	for _, item := range splitList(s) {
+		n, err := parseIPNet(item)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// parseIPList parses a comma-separated list of addresses, appending them
+// to ips.  Ranges of the form "10.0.0.1-10.0.0.5" are expanded if the
+// Decoder has IPRanges set, up to maxIPs addresses in all (or the
+// Decoder's MaxSliceLen limit, if lower).
+func (d *Decoder) parseIPList(s string, ips []net.IP) ([]net.IP, error) {
+	max := maxIPs
+	if l := d.limits.MaxSliceLen; l > 0 && l < max {
+		max = l
+	}
+	for _, item := range splitList(s) {
+		if !d.ipRanges || !strings.Contains(item, "-") {
+			if len(ips) >= max {
+				return nil, fmt.Errorf("IP list exceeds %d addresses", max)
+			}
+			ip, err := parseIP(item)
+			if err != nil {
+				return nil, err
+			}
+			ips = append(ips, ip)
+			continue
+		}
+		var err error
+		if ips, err = appendIPRange(ips, item, max); err != nil {
+			return nil, err
+		}
+	}
+	return ips, nil
+}
+
+// appendIPRange appends the addresses covered by "first-last" range s
+// (inclusive) to ips, failing if ips would grow beyond max
+func appendIPRange(ips []net.IP, s string, max int) ([]net.IP, error) {
+	dash := strings.Index(s, "-")
+	first, err := parseIP(s[:dash])
+	if err != nil {
+		return nil, err
+	}
+	last, err := parseIP(s[dash+1:])
+	if err != nil {
+		return nil, err
+	}
+	if (first.To4() == nil) != (last.To4() == nil) {
+		return nil, errorf(ErrInvalidValue, "IP range %q mixes IPv4 and IPv6", s)
+	}
+	if f4 := first.To4(); f4 != nil {
+		first, last = f4, last.To4()
+	}
+	if bytes.Compare(first, last) > 0 {
+		return nil, errorf(ErrInvalidValue, "IP range %q is reversed", s)
+	}
+
+	for ip := first; ; ip = nextIP(ip) {
+		if len(ips) >= max {
+			return nil, fmt.Errorf("IP range %q takes the list beyond %d addresses", s, max)
+		}
+		ips = append(ips, ip)
+		if ip.Equal(last) {
+			break
+		}
+	}
+	return ips, nil
+}
+
+// nextIP returns a copy of ip incremented by one
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// unmarshallIPs fills a []net.IP from a slice source, splitting any string
+// elements which hold lists (or ranges, as per IPRanges)
+func (d *Decoder) unmarshallIPs(vto reflect.Value, vfrom reflect.Value) error {
+	var ips []net.IP
+	for j := 0; j < vfrom.Len(); j++ {
+		elem := vfrom.Index(j)
+		if elem.Kind() == reflect.String {
+			var err error
+			if ips, err = d.parseIPList(elem.String(), ips); err != nil {
+				return err
+			}
+			continue
+		}
+		var ip net.IP
+		if err := d.unmarshall(reflect.ValueOf(&ip).Elem(), elem); err != nil {
+			return err
+		}
+		ips = append(ips, ip)
+	}
+	vto.Set(reflect.ValueOf(ips))
+	return nil
+}
+
+// splitList splits a comma-separated string, trimming whitespace and
+// dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
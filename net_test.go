@@ -0,0 +1,133 @@
+package coerce
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func Test_IPNet_list(t *testing.T) {
+	type x struct {
+		Allow []net.IPNet
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"allow": "10.0.0.0/8, 192.168.1.7, fd00::/8",
+	})
+
+	expected := []net.IPNet{
+		{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+		{IP: net.IP{192, 168, 1, 7}, Mask: net.CIDRMask(32, 32)},
+		{IP: net.ParseIP("fd00::"), Mask: net.CIDRMask(8, 128)},
+	}
+	report(err, expected, myx.Allow, t)
+}
+
+func Test_IP_range(t *testing.T) {
+	var ips []net.IP
+	d := NewDecoder(IPRanges())
+	err := d.Var(&ips, []string{"10.0.0.254-10.0.1.1", "::1"})
+
+	expected := []net.IP{
+		{10, 0, 0, 254}, {10, 0, 0, 255}, {10, 0, 1, 0}, {10, 0, 1, 1},
+		net.ParseIP("::1"),
+	}
+	report(err, expected, ips, t)
+
+	if err := d.Var(&ips, "10.0.0.5-10.0.0.1"); err == nil {
+		t.Errorf("expected error for reversed range")
+	}
+
+	// ranges are only expanded on request
+	if err := Var(&ips, "10.0.0.1-10.0.0.5"); err == nil {
+		t.Errorf("expected error for range without IPRanges")
+	}
+
+	// the cap applies to the list, not each range
+	if err := d.Var(&ips, "10.0.0.0-10.0.255.255,10.1.0.0-10.1.0.1"); err == nil {
+		t.Errorf("expected error for list beyond %d addresses", maxIPs)
+	}
+	err = d.Var(&ips, "10.0.0.0-10.0.255.255")
+	report(err, maxIPs, len(ips), t)
+}
+
+func Test_HostPort(t *testing.T) {
+	type x struct {
+		Listen HostPort
+		DNS    net.UDPAddr
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"listen": "0.0.0.0:8080",
+		"dns":    "[::1]:53",
+	})
+
+	expected := x{
+		Listen: HostPort{Host: "0.0.0.0", Port: 8080},
+		DNS:    net.UDPAddr{IP: net.ParseIP("::1"), Port: 53},
+	}
+	report(err, expected, myx, t)
+
+	for _, bad := range []string{"localhost", "host:99999", "bad host:80"} {
+		if err := Var(&myx.Listen, bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func Test_netip(t *testing.T) {
+	type x struct {
+		Addr   netip.Addr
+		Listen netip.AddrPort
+		Allow  []netip.Prefix
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"addr":   " fe80::1%eth0",
+		"listen": "127.0.0.1:8080",
+		"allow":  []string{"10.0.0.0/8", "192.168.1.7"},
+	})
+
+	expected := x{
+		Addr:   netip.MustParseAddr("fe80::1%eth0"),
+		Listen: netip.MustParseAddrPort("127.0.0.1:8080"),
+		Allow:  []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.7/32")},
+	}
+	report(err, expected, myx, t)
+
+	if err := Var(&myx.Addr, "10.0.0.256"); err == nil {
+		t.Errorf("expected error for invalid address")
+	}
+}
+
+func Test_net_types(t *testing.T) {
+	type x struct {
+		Subnet net.IPNet
+		Route  *net.IPNet
+		MAC    net.HardwareAddr
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"subnet": "10.1.0.0/16",
+		"route":  "2001:db8::/32",
+		"mac":    "00:1a:2b:3c:4d:5e",
+	})
+	report(err, "10.1.0.0/16", myx.Subnet.String(), t)
+	if err == nil && myx.Route != nil {
+		report(err, "2001:db8::/32", myx.Route.String(), t)
+	} else {
+		t.Errorf("expected Route to be set, got %v (%v)", myx.Route, err)
+	}
+	report(err, "00:1a:2b:3c:4d:5e", myx.MAC.String(), t)
+
+	if err := Var(&myx.MAC, "not a mac"); err == nil {
+		t.Errorf("expected error for invalid MAC")
+	}
+	if err := Var(&myx.Subnet, "10.1.0.0/33"); err == nil {
+		t.Errorf("expected error for invalid CIDR")
+	}
+}
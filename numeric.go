@@ -0,0 +1,298 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// intRange returns the minimum and maximum values of signed integer type t
+func intRange(t reflect.Type) (int64, int64) {
+	max := int64(1)<<(t.Bits()-1) - 1
+	return -max - 1, max
+}
+
+// uintMax returns the maximum value of unsigned integer type t
+func uintMax(t reflect.Type) uint64 {
+	return math.MaxUint64 >> (64 - t.Bits())
+}
+
+// RoundingMode selects how fractional values are converted to integers
+type RoundingMode int
+
+const (
+	// RoundTruncate discards the fraction, rounding towards zero
+	RoundTruncate RoundingMode = iota
+	// RoundHalfUp rounds to the nearest integer, with halves rounded away
+	// from zero (2.5 -> 3, -2.5 -> -3)
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest integer, with halves rounded to
+	// the even neighbour (2.5 -> 2, 3.5 -> 4), aka banker's rounding
+	RoundHalfEven
+	// RoundFloor rounds towards negative infinity
+	RoundFloor
+	// RoundCeil rounds towards positive infinity
+	RoundCeil
+)
+
+// round applies the Decoder's rounding mode to f
+func (d *Decoder) round(f float64) float64 {
+	switch d.rounding {
+	case RoundHalfUp:
+		return math.Round(f)
+	case RoundHalfEven:
+		return math.RoundToEven(f)
+	case RoundFloor:
+		return math.Floor(f)
+	case RoundCeil:
+		return math.Ceil(f)
+	}
+	return math.Trunc(f)
+}
+
+// floatMax returns the largest finite value of float type t
+func floatMax(t reflect.Type) float64 {
+	if t.Bits() == 32 {
+		return math.MaxFloat32
+	}
+	return math.MaxFloat64
+}
+
+// hasBasePrefix reports whether s, after any sign, starts with one of the
+// integer base prefixes 0x, 0o or 0b
+func hasBasePrefix(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return len(s) > 2 && s[0] == '0' && strings.IndexByte("xXoObB", s[1]) >= 0
+}
+
+// isRangeErr reports whether err is a strconv out-of-range error
+func isRangeErr(err error) bool {
+	return errors.Is(err, strconv.ErrRange)
+}
+
+// parseError returns strconv error err, marked as ErrOverflow if it is an
+// out-of-range error and otherwise as ErrInvalidValue
+func parseError(err error) error {
+	if isRangeErr(err) {
+		return &kindError{ErrOverflow, err}
+	}
+	return &kindError{ErrInvalidValue, err}
+}
+
+// overflowError reports that v is outside the range of numeric type t
+func overflowError(v interface{}, t reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		min, max := intRange(t)
+		return errorf(ErrOverflow, "value %v overflows %v (range %d to %d)", v, t, min, max)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return errorf(ErrOverflow, "value %v overflows %v (range 0 to %d)", v, t, uintMax(t))
+	}
+	return errorf(ErrOverflow, "value %v overflows %v (range ±%g)", v, t, floatMax(t))
+}
+
+// setInt stores i in signed integer vto.  Values outside vto's range are
+// an error unless the Decoder has Saturate set, in which case they are
+// clamped.
+func (d *Decoder) setInt(vto reflect.Value, i int64) error {
+	if vto.OverflowInt(i) {
+		if !d.saturate {
+			return overflowError(i, vto.Type())
+		}
+		min, max := intRange(vto.Type())
+		if i < min {
+			i = min
+		} else {
+			i = max
+		}
+	}
+	vto.SetInt(i)
+	return nil
+}
+
+// setUint is setInt for unsigned integer vto
+func (d *Decoder) setUint(vto reflect.Value, u uint64) error {
+	if vto.OverflowUint(u) {
+		if !d.saturate {
+			return overflowError(u, vto.Type())
+		}
+		u = uintMax(vto.Type())
+	}
+	vto.SetUint(u)
+	return nil
+}
+
+// setIntFromFloat stores f, truncated, in signed integer vto, with range
+// checks as per setInt
+func (d *Decoder) setIntFromFloat(vto reflect.Value, f float64) error {
+	if math.IsNaN(f) {
+		return errorf(ErrInvalidValue, "can't store NaN in %v", vto.Type())
+	}
+	min, max := intRange(vto.Type())
+	if f < float64(min) || f >= -float64(min) {
+		if !d.saturate {
+			return overflowError(f, vto.Type())
+		}
+		if f < 0 {
+			vto.SetInt(min)
+		} else {
+			vto.SetInt(max)
+		}
+		return nil
+	}
+	vto.SetInt(int64(f))
+	return nil
+}
+
+// setUintFromFloat stores non-negative f, truncated, in unsigned integer
+// vto, with range checks as per setInt
+func (d *Decoder) setUintFromFloat(vto reflect.Value, f float64) error {
+	if math.IsNaN(f) {
+		return errorf(ErrInvalidValue, "can't store NaN in %v", vto.Type())
+	}
+	if f >= math.Ldexp(1, vto.Type().Bits()) {
+		if !d.saturate {
+			return overflowError(f, vto.Type())
+		}
+		vto.SetUint(uintMax(vto.Type()))
+		return nil
+	}
+	vto.SetUint(uint64(f))
+	return nil
+}
+
+// setFloat stores f in vto.  Finite values which overflow a float32 are an
+// error unless the Decoder has Saturate set, in which case they are
+// clamped.
+func (d *Decoder) setFloat(vto reflect.Value, f float64) error {
+	if !math.IsInf(f, 0) && vto.OverflowFloat(f) {
+		if !d.saturate {
+			return overflowError(f, vto.Type())
+		}
+		f = math.Copysign(floatMax(vto.Type()), f)
+	}
+	vto.SetFloat(f)
+	return nil
+}
+
+var jsonNumberType = reflect.TypeOf(json.Number(""))
+
+// numberValue returns the int64, uint64 (if too big for an int64) or
+// float64 value of number literal s, such as a json.Number, so that
+// integers are never rounded through a float
+func numberValue(s string) (reflect.Value, bool) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return reflect.ValueOf(i), true
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return reflect.ValueOf(u), true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return reflect.ValueOf(f), true
+	}
+	return reflect.Value{}, false
+}
+
+// countTrue returns the number of true elements of slice or array v, as
+// for a flag given several times, if its elements are all bools
+func countTrue(v reflect.Value) (int64, bool) {
+	if v.Len() == 0 {
+		return 0, v.Type().Elem().Kind() == reflect.Bool
+	}
+	n := int64(0)
+	for j := 0; j < v.Len(); j++ {
+		e := v.Index(j)
+		if e.Kind() == reflect.Interface {
+			e = e.Elem()
+		}
+		if e.Kind() != reflect.Bool {
+			return 0, false
+		}
+		if e.Bool() {
+			n++
+		}
+	}
+	return n, true
+}
+
+// unmarshallInt stores i, taken from an integer of some other type (such
+// as an int32 from a database driver, or a named type), in vto, with range
+// checks as per setInt
+func (d *Decoder) unmarshallInt(vto reflect.Value, tto reflect.Type, i int64) error {
+
+	switch tto.Kind() {
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return d.setInt(vto, i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i < 0 {
+			return d.unmarshallNegative(vto, tto, i)
+		}
+		return d.unmarshallUint(vto, tto, uint64(i))
+
+	case reflect.Float32, reflect.Float64:
+		return d.setFloat(vto, float64(i))
+
+	case reflect.Bool:
+		// a count of repeated flags
+		if !d.strictBool {
+			vto.SetBool(i > 0)
+			return nil
+		}
+	}
+
+	return errorf(ErrUnsupportedConversion, "don't know how to unmarshall int to %v", tto)
+}
+
+// unmarshallUint is unmarshallInt for unsigned integer sources
+func (d *Decoder) unmarshallUint(vto reflect.Value, tto reflect.Type, u uint64) error {
+
+	switch tto.Kind() {
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if u > math.MaxInt64 {
+			if !d.saturate {
+				return overflowError(u, tto)
+			}
+			u = math.MaxInt64
+		}
+		return d.setInt(vto, int64(u))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return d.setUint(vto, u)
+
+	case reflect.Float32, reflect.Float64:
+		return d.setFloat(vto, float64(u))
+
+	case reflect.Bool:
+		if !d.strictBool {
+			vto.SetBool(u > 0)
+			return nil
+		}
+	}
+
+	return errorf(ErrUnsupportedConversion, "don't know how to unmarshall uint to %v", tto)
+}
@@ -0,0 +1,264 @@
+package coerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Saturate(t *testing.T) {
+	sat := NewDecoder(Saturate())
+
+	var i8 int8
+	err := sat.Var(&i8, "300")
+	report(err, int8(127), i8, t)
+	err = sat.Var(&i8, -1e10)
+	report(err, int8(-128), i8, t)
+	err = sat.Var(&i8, "1k")
+	report(err, int8(127), i8, t)
+
+	var u16 uint16
+	err = sat.Var(&u16, "70000")
+	report(err, uint16(math.MaxUint16), u16, t)
+	err = sat.Var(&u16, -7.0)
+	report(err, uint16(0), u16, t)
+
+	var u64 uint64
+	err = sat.Var(&u64, 1e30)
+	report(err, uint64(math.MaxUint64), u64, t)
+
+	var f32 float32
+	err = sat.Var(&f32, 1e300)
+	report(err, float32(math.MaxFloat32), f32, t)
+	err = sat.Var(&f32, "-1e300")
+	report(err, float32(-math.MaxFloat32), f32, t)
+}
+
+func Test_Rounding(t *testing.T) {
+	from := []float64{2.5, 3.5, -2.5, 2.4, -2.6}
+	for mode, expected := range map[RoundingMode][]int{
+		RoundTruncate: {2, 3, -2, 2, -2},
+		RoundHalfUp:   {3, 4, -3, 2, -3},
+		RoundHalfEven: {2, 4, -2, 2, -3},
+		RoundFloor:    {2, 3, -3, 2, -3},
+		RoundCeil:     {3, 4, -2, 3, -2},
+	} {
+		var got []int
+		err := NewDecoder(Rounding(mode)).Var(&got, from)
+		report(err, expected, got, t)
+	}
+}
+
+func Test_Var_cross_kind_int(t *testing.T) {
+	type count int16
+
+	var i int
+	err := Var(&i, int32(42))
+	report(err, 42, i, t)
+
+	var c count
+	err = Var(&c, uint64(7))
+	report(err, count(7), c, t)
+
+	var u uint8
+	err = Var(&u, count(200))
+	report(err, uint8(200), u, t)
+
+	var f float64
+	err = Var(&f, int64(-3))
+	report(err, float64(-3), f, t)
+
+	if err := Var(&c, 40000); err == nil {
+		t.Errorf("expected overflow error, got %v", c)
+	}
+	if err := Var(&i, uint64(math.MaxUint64)); err == nil {
+		t.Errorf("expected overflow error, got %v", i)
+	}
+	if err := Var(&u, -1); err == nil {
+		t.Errorf("expected error for negative value, got %v", u)
+	}
+
+	err = NewDecoder(Saturate()).Var(&c, 40000)
+	report(err, count(math.MaxInt16), c, t)
+}
+
+func Test_Var_int_base_prefix(t *testing.T) {
+	var i int
+	for s, expected := range map[string]int{
+		"0x1F": 31, "0o755": 493, "0b1010": 10, "-0x10": -16, "0755": 755, "0x_ff": 255,
+	} {
+		err := Var(&i, s)
+		report(err, expected, i, t)
+	}
+
+	var u uint16
+	err := Var(&u, "0xFFFF")
+	report(err, uint16(0xFFFF), u, t)
+	if err := Var(&u, "0x10000"); err == nil {
+		t.Errorf("expected range error, got %v", u)
+	}
+}
+
+func Test_Var_overflow(t *testing.T) {
+	var i8 int8
+	err := Var(&i8, 300.0)
+	report(nil, "value 300 overflows int8 (range -128 to 127)", fmt.Sprint(err), t)
+
+	var u16 uint16
+	err = Var(&u16, "64k")
+	report(nil, "value 65536 overflows uint16 (range 0 to 65535)", fmt.Sprint(err), t)
+
+	var i64 int64
+	if err := Var(&i64, 1e19); err == nil {
+		t.Errorf("expected overflow error, got %v", i64)
+	}
+	if err := Var(&i64, math.NaN()); err == nil {
+		t.Errorf("expected error for NaN, got %v", i64)
+	}
+
+	var f32 float32
+	if err := Var(&f32, 1e300); err == nil {
+		t.Errorf("expected overflow error, got %v", f32)
+	}
+
+	err = Var(&i8, 127.9)
+	report(err, int8(127), i8, t)
+}
+
+func Test_Var_json_Number(t *testing.T) {
+	var i int
+	err := Var(&i, json.Number("1e3"))
+	report(err, 1000, i, t)
+
+	var u uint64
+	err = Var(&u, json.Number("18446744073709551615"))
+	report(err, uint64(math.MaxUint64), u, t)
+
+	var f float64
+	err = Var(&f, json.Number("-2.5"))
+	report(err, -2.5, f, t)
+
+	var s string
+	err = Var(&s, json.Number("1.50"))
+	report(err, "1.50", s, t)
+
+	var i8 int8
+	err = Var(&i8, json.Number("300"))
+	report(nil, "value 300 overflows int8 (range -128 to 127)", fmt.Sprint(err), t)
+
+	var ts time.Time
+	err = Var(&ts, json.Number("86400"))
+	report(err, int64(86400), ts.Unix(), t)
+
+	var o struct {
+		N    int
+		List []uint8
+	}
+	dec := json.NewDecoder(strings.NewReader(`{"n": 7, "list": [1, 2]}`))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	err = Struct(&o, m)
+	report(err, 7, o.N, t)
+	report(err, []uint8{1, 2}, o.List, t)
+}
+
+func Test_getBytes(t *testing.T) {
+	for s, want := range map[string]int64{
+		"512":    512,
+		"10B":    10,
+		"0.5k":   512,
+		"2K":     2048,
+		"10MB":   10e6,
+		"10mb":   10e6,
+		"1.5 GB": 1.5e9,
+		"3TB":    3e12,
+		"1PB":    1e15,
+		"512KiB": 512 << 10,
+		"4MiB":   4 << 20,
+		"2GiB":   2 << 30,
+		"1TiB":   1 << 40,
+		"1PiB":   1 << 50,
+		"256Mi":  256 << 20,
+		"-1k":    -1024,
+	} {
+		var i int64
+		err := Var(&i, s)
+		report(err, want, i, t)
+	}
+
+	si := NewDecoder(SIByteUnits())
+	var i int64
+	err := si.Var(&i, "10M")
+	report(err, int64(10e6), i, t)
+	err = si.Var(&i, "10MiB")
+	report(err, int64(10<<20), i, t)
+
+	for _, s := range []string{"", "k", "10XB", "10 MBs", "10000PB"} {
+		if err := Var(&i, s); err == nil {
+			t.Errorf("expected error for %q, got %v", s, i)
+		}
+	}
+}
+
+func Test_Var_percent(t *testing.T) {
+	var f float64
+	err := Var(&f, "75%")
+	report(err, 0.75, f, t)
+	err = Var(&f, " 12.5 % ")
+	report(err, 0.125, f, t)
+
+	var f32 float32
+	err = Var(&f32, "50%")
+	report(err, float32(0.5), f32, t)
+
+	err = NewDecoder(KeepPercent()).Var(&f, "75%")
+	report(err, 75.0, f, t)
+
+	for _, s := range []string{"%", "x%", "75%%"} {
+		if err := Var(&f, s); err == nil {
+			t.Errorf("expected error for %q, got %v", s, f)
+		}
+	}
+}
+
+func Test_Struct_counted_flags(t *testing.T) {
+	type x struct {
+		Verbose int
+		Quiet   uint8
+		Debug   int
+		Once    int
+		Never   int
+		Trace   bool
+		Color   bool
+		Force   bool
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"verbose": []bool{true, true, true},
+		"quiet":   []interface{}{true, false, true},
+		"debug":   true,
+		"once":    []bool{true},
+		"never":   []bool{},
+		"trace":   2,
+		"color":   0,
+		"force":   uint(1),
+	})
+	expected := x{Verbose: 3, Quiet: 2, Debug: 1, Once: 1, Trace: true, Force: true}
+	report(err, expected, myx, t)
+
+	var b bool
+	if err := NewDecoder(StrictBool()).Var(&b, 1); err == nil {
+		t.Errorf("expected error for a count with StrictBool")
+	}
+	var n int
+	if err := Var(&n, []interface{}{true, "x"}); err == nil {
+		t.Errorf("expected error for mixed values")
+	}
+}
@@ -0,0 +1,101 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// pairSeparators returns the `sep=` and `kvsep=` tag options of a map
+// field, and whether either is set
+func pairSeparators(tag fieldTag) (string, string, bool) {
+	sep, ok := tag.option("sep")
+	kvsep, kvok := tag.option("kvsep")
+	return sep, kvsep, ok || kvok
+}
+
+// unmarshallPairs coerces vfrom, a list of "key=value" strings or a string
+// of them split on sep (else the Decoder's Separator, else ","), into a
+// new map of vto's type.  Keys and values are split on the first kvsep
+// (else the Decoder's KeyValueSeparator, else "=") and trimmed of
+// surrounding spaces; later keys replace earlier ones.
+func (d *Decoder) unmarshallPairs(vto reflect.Value, vfrom reflect.Value, sep string, kvsep string) error {
+	if sep == "" {
+		sep = d.separator
+	}
+	if kvsep == "" {
+		kvsep = d.kvSeparator
+	}
+	if kvsep == "" {
+		kvsep = "="
+	}
+
+	var items []string
+	if vfrom.Kind() == reflect.String {
+		if s := vfrom.String(); strings.TrimSpace(s) != "" {
+			if sep == "" {
+				sep = ","
+			}
+			if err := d.limits.checkMapSize(strings.Count(s, sep) + 1); err != nil {
+				return err
+			}
+			items = strings.Split(s, sep)
+		}
+	} else {
+		if err := d.limits.checkMapSize(vfrom.Len()); err != nil {
+			return err
+		}
+		items = make([]string, vfrom.Len())
+		for j := range items {
+			e := vfrom.Index(j)
+			if e.Kind() == reflect.Interface {
+				e = e.Elem()
+			}
+			if e.Kind() != reflect.String {
+				return errorf(ErrUnsupportedConversion, "can't coerce %v element %d into %v: expected \"key%svalue\"", vfrom.Type(), j, vto.Type(), kvsep)
+			}
+			items[j] = e.String()
+		}
+	}
+
+	tto := vto.Type()
+	m := reflect.MakeMapWithSize(tto, len(items))
+	for j, item := range items {
+		ks, vs, ok := strings.Cut(item, kvsep)
+		if !ok {
+			return &elemError{fmt.Sprintf("[%d]", j), fmt.Sprintf("element %d", j),
+				errorf(ErrInvalidValue, "expected \"key%svalue\", got %q", kvsep, item)}
+		}
+		ks, vs = strings.TrimSpace(ks), strings.TrimSpace(vs)
+		k := reflect.New(tto.Key()).Elem()
+		if err := d.unmarshall(k, reflect.ValueOf(ks)); err != nil {
+			return &elemError{fmt.Sprintf("[%d]", j), fmt.Sprintf("element %d", j), err}
+		}
+		v := reflect.New(tto.Elem()).Elem()
+		if err := d.unmarshall(v, reflect.ValueOf(vs)); err != nil {
+			return &elemError{fmt.Sprintf("[%s]", ks), fmt.Sprintf("map key %s", ks), err}
+		}
+		m.SetMapIndex(k, v)
+	}
+	vto.Set(m)
+	return nil
+}
@@ -0,0 +1,72 @@
+package coerce
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func Test_Struct_pairs(t *testing.T) {
+	type x struct {
+		Labels  map[string]string
+		Limits  map[string]int
+		Weights map[string]float64 `coerce:",sep=;,kvsep=:"`
+		Ports   map[int]string
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"labels":  []string{"env=prod", "tier = web", "note=a=b"},
+		"limits":  "conns=1k, rate=50",
+		"weights": "a:0.5; b:25%",
+		"ports":   []interface{}{"80=http", "443=https"},
+	})
+	expected := x{
+		Labels:  map[string]string{"env": "prod", "tier": "web", "note": "a=b"},
+		Limits:  map[string]int{"conns": 1024, "rate": 50},
+		Weights: map[string]float64{"a": 0.5, "b": 0.25},
+		Ports:   map[int]string{80: "http", 443: "https"},
+	}
+	report(err, expected, myx, t)
+
+	var m map[string]string
+	err = NewDecoder(Separator(" "), KeyValueSeparator(":")).Var(&m, "a:1 b:2")
+	report(err, map[string]string{"a": "1", "b": "2"}, m, t)
+
+	err = Var(&m, "")
+	report(err, map[string]string{}, m, t)
+
+	for _, from := range []interface{}{"a=1,b", []interface{}{"a=1", 2}} {
+		if err := Var(&m, from); err == nil {
+			t.Errorf("expected error for %v", from)
+		}
+	}
+	if err := Struct(&myx, map[string]interface{}{"limits": "conns=many"}); err == nil {
+		t.Errorf("expected error for a bad value")
+	} else {
+		report(nil, "field Limits (map[string]int) from key \"limits\": can't coerce \"conns=many\" (string): map key conns: "+
+			"strconv.ParseInt: parsing \"many\": invalid syntax", err.Error(), t)
+	}
+}
+
+func Test_pairs_command_line(t *testing.T) {
+	type opts struct {
+		Label map[string]string `coerce:",short=l"`
+	}
+
+	var a opts
+	err := Args(&a, []string{"--label", "env=prod", "-l", "tier=web,zone=b"})
+	report(err, map[string]string{"env": "prod", "tier": "web,zone=b"}, a.Label, t)
+
+	err = Args(&a, []string{"--label=env=prod,tier=web"})
+	report(err, map[string]string{"env": "prod", "tier": "web"}, a.Label, t)
+
+	f := opts{Label: map[string]string{"default": "x"}}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := RegisterFlags(fs, &f); err != nil {
+		t.Fatal(err)
+	}
+	err = fs.Parse([]string{"-label", "env=prod,tier=web", "-l", "zone=b"})
+	report(err, map[string]string{"env": "prod", "tier": "web", "zone": "b"}, f.Label, t)
+}
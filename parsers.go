@@ -0,0 +1,109 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// stringParser parses s into a value of the type it is held for in
+// stringParsers
+type stringParser func(d *Decoder, s string) (interface{}, error)
+
+// stringParsers holds coerce's own string parsers for non-builtin types.
+// These take precedence over encoding.TextUnmarshaler (being generally
+// more lenient) but not over registered converters.
+var stringParsers = map[reflect.Type]stringParser{
+	reflect.TypeOf(time.Duration(0)): func(_ *Decoder, s string) (interface{}, error) {
+		return parseDuration(s)
+	},
+	reflect.TypeOf(time.Time{}): func(d *Decoder, s string) (interface{}, error) {
+		return d.parseTime(s)
+	},
+
+	reflect.TypeOf(net.IP{}): func(_ *Decoder, s string) (interface{}, error) {
+		return parseIP(s)
+	},
+	reflect.TypeOf([]net.IP{}): func(d *Decoder, s string) (interface{}, error) {
+		return d.parseIPList(s, nil)
+	},
+	reflect.TypeOf(net.IPNet{}): func(_ *Decoder, s string) (interface{}, error) {
+		return parseIPNet(s)
+	},
+	reflect.TypeOf([]net.IPNet{}): func(_ *Decoder, s string) (interface{}, error) {
+		return parseIPNetList(s)
+	},
+	reflect.TypeOf(net.HardwareAddr{}): func(_ *Decoder, s string) (interface{}, error) {
+		return net.ParseMAC(strings.TrimSpace(s))
+	},
+	reflect.TypeOf(netip.Addr{}): func(_ *Decoder, s string) (interface{}, error) {
+		return netip.ParseAddr(strings.TrimSpace(s))
+	},
+	reflect.TypeOf(netip.AddrPort{}): func(_ *Decoder, s string) (interface{}, error) {
+		return netip.ParseAddrPort(strings.TrimSpace(s))
+	},
+	reflect.TypeOf(netip.Prefix{}): func(_ *Decoder, s string) (interface{}, error) {
+		return parsePrefix(s)
+	},
+	reflect.TypeOf(HostPort{}): func(_ *Decoder, s string) (interface{}, error) {
+		return parseHostPort(s)
+	},
+	reflect.TypeOf(net.TCPAddr{}): func(_ *Decoder, s string) (interface{}, error) {
+		ip, port, zone, err := parseIPPort(s)
+		return net.TCPAddr{IP: ip, Port: port, Zone: zone}, err
+	},
+	reflect.TypeOf(net.UDPAddr{}): func(_ *Decoder, s string) (interface{}, error) {
+		ip, port, zone, err := parseIPPort(s)
+		return net.UDPAddr{IP: ip, Port: port, Zone: zone}, err
+	},
+
+	reflect.TypeOf(url.URL{}): func(_ *Decoder, s string) (interface{}, error) {
+		u, err := url.Parse(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	},
+
+	reflect.TypeOf(mail.Address{}): func(_ *Decoder, s string) (interface{}, error) {
+		a, err := mail.ParseAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		return *a, nil
+	},
+	reflect.TypeOf([]mail.Address{}): func(_ *Decoder, s string) (interface{}, error) {
+		list, err := mail.ParseAddressList(s)
+		if err != nil {
+			return nil, err
+		}
+		as := make([]mail.Address, len(list))
+		for i, a := range list {
+			as[i] = *a
+		}
+		return as, nil
+	},
+}
@@ -0,0 +1,85 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// isPointer reports whether key looks like a JSON Pointer
+func isPointer(key string) bool {
+	return strings.HasPrefix(key, "/")
+}
+
+// lookupKey returns the value for key in 'from', trying it literally
+// first and then, if it starts with "/", as a JSON Pointer
+func lookupKey(from map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := from[key]; ok {
+		return v, true
+	}
+	if !isPointer(key) {
+		return nil, false
+	}
+	return resolvePointer(from, key)
+}
+
+// topKey returns the key of 'from' which a lookupKey of key reads: key
+// itself, or the first reference token of a JSON Pointer
+func topKey(from map[string]interface{}, key string) string {
+	if _, ok := from[key]; ok || !isPointer(key) {
+		return key
+	}
+	tok, _, _ := strings.Cut(key[1:], "/")
+	return strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+}
+
+// resolvePointer evaluates JSON Pointer ptr (eg "/servers/0/host") against
+// doc, descending through maps with string (or interface{}) keys and slices
+func resolvePointer(doc interface{}, ptr string) (interface{}, bool) {
+	if ptr == "" {
+		return doc, true
+	}
+	cur := doc
+	for _, tok := range strings.Split(ptr[1:], "/") {
+		tok = strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+
+		if m, ok := stringMap(reflect.ValueOf(cur)); ok {
+			if cur, ok = m[tok]; !ok {
+				return nil, false
+			}
+			continue
+		}
+
+		v := reflect.ValueOf(cur)
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= v.Len() || tok != strconv.Itoa(i) {
+				return nil, false
+			}
+			cur = v.Index(i).Interface()
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
@@ -0,0 +1,31 @@
+package coerce
+
+import "testing"
+
+func Test_Struct_json_pointer(t *testing.T) {
+	doc := map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a.example.com", "port": "80"},
+		},
+		"db":       map[string]interface{}{"user": "admin", "a/b": 1},
+		"/verbose": true,
+	}
+
+	type x struct {
+		Host    string `coerce:"/servers/0/host"`
+		Port    int    `coerce:"/servers/0/port"`
+		Slashed int    `coerce:"/db/a~1b"`
+		Missing string `coerce:"/servers/1/host"`
+	}
+	var myx x
+	err := Struct(&myx, doc)
+	report(err, x{"a.example.com", 80, 1, ""}, myx, t)
+
+	type y struct {
+		User    string
+		Verbose bool
+	}
+	var myy y
+	err = Struct(&myy, doc, "/db/%s", "/%s")
+	report(err, y{"admin", true}, myy, t)
+}
@@ -0,0 +1,82 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// scratch holds the working storage for decoding one struct.  It is
+// pooled so that services decoding on every request don't pay for fresh
+// allocations each time.
+type scratch struct {
+	buf      []byte   // candidate key being built
+	found    []string // keys matched for the current field
+	assigned []string // fields assigned so far
+	failed   []string // fields failed so far
+	used     []string // map keys matched so far
+	errs     []error  // errors so far
+}
+
+var scratchPool = sync.Pool{
+	New: func() interface{} { return new(scratch) },
+}
+
+// getScratch returns empty scratch storage from the pool
+func getScratch() *scratch {
+	return scratchPool.Get().(*scratch)
+}
+
+// release empties sc and returns it to the pool
+func (sc *scratch) release() {
+	for i := range sc.errs {
+		sc.errs[i] = nil // don't pin errors in the pool
+	}
+	sc.buf = sc.buf[:0]
+	sc.found = sc.found[:0]
+	sc.assigned = sc.assigned[:0]
+	sc.failed = sc.failed[:0]
+	sc.used = sc.used[:0]
+	sc.errs = sc.errs[:0]
+	scratchPool.Put(sc)
+}
+
+// appendKey appends key format pat applied to name to buf.  The common
+// case of a single "%s" is handled without fmt.
+func appendKey(buf []byte, pat string, name string) []byte {
+	if i := strings.Index(pat, "%s"); i >= 0 && strings.Count(pat, "%") == 1 {
+		buf = append(buf, pat[:i]...)
+		buf = append(buf, name...)
+		return append(buf, pat[i+2:]...)
+	}
+	return fmt.Appendf(buf, pat, name)
+}
+
+// containsBytes reports whether list contains the string held in b
+func containsBytes(list []string, b []byte) bool {
+	for _, l := range list {
+		if l == string(b) {
+			return true
+		}
+	}
+	return false
+}
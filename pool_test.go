@@ -0,0 +1,27 @@
+package coerce
+
+import "testing"
+
+func Benchmark_Struct(b *testing.B) {
+	type x struct {
+		Name    string
+		Count   int
+		Ratio   float64
+		Enabled bool
+		Missing string
+	}
+	mymap := map[string]interface{}{
+		"--name":    "widget",
+		"--count":   "12",
+		"--ratio":   0.5,
+		"--enabled": true,
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var myx x
+		if err := Struct(&myx, mymap, "--%s", "-%s"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
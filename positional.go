@@ -0,0 +1,56 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import "strconv"
+
+// Positional coerces a list of positional values (eg command line
+// arguments) into the fields of the structure pointed to by 'to' which are
+// tagged `coerce:",pos"` or `coerce:",pos=N"`; see Struct
+func Positional(to interface{}, args []string) error {
+	return Struct(to, positionalMap(args))
+}
+
+// positionalMap returns a map with args[i] under key "i"
+func positionalMap(args []string) map[string]interface{} {
+	m := make(map[string]interface{}, len(args))
+	for i, a := range args {
+		m[strconv.Itoa(i)] = a
+	}
+	return m
+}
+
+// positionalVal returns the value for position n from 'from', or if 'rest'
+// is set the list of values for positions n, n+1, ... up to the first gap
+func positionalVal(from map[string]interface{}, n int, rest bool) (interface{}, bool) {
+	if !rest {
+		v, ok := from[strconv.Itoa(n)]
+		return v, ok
+	}
+	var list []interface{}
+	for ; ; n++ {
+		v, ok := from[strconv.Itoa(n)]
+		if !ok {
+			break
+		}
+		list = append(list, v)
+	}
+	return list, list != nil
+}
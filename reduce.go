@@ -0,0 +1,124 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// reduce combines the elements of slice vfrom into scalar vto using the
+// reducer named by 'how': sum, min, max, first, last or join.  An empty
+// slice leaves vto unchanged.
+func (d *Decoder) reduce(vto reflect.Value, vfrom reflect.Value, how string, sep string) error {
+	n := vfrom.Len()
+	if n == 0 {
+		return nil
+	}
+
+	switch how {
+	case "first":
+		return d.unmarshall(vto, vfrom.Index(0))
+	case "last":
+		return d.unmarshall(vto, vfrom.Index(n-1))
+	case "join":
+		if vto.Kind() != reflect.String {
+			return errorf(ErrUnsupportedConversion, "can't join into %v", vto.Type())
+		}
+		if sep == "" {
+			sep = ","
+		}
+		parts := make([]string, n)
+		for j := range parts {
+			if err := d.Var(&parts[j], vfrom.Index(j).Interface()); err != nil {
+				return err
+			}
+		}
+		vto.SetString(strings.Join(parts, sep))
+		return nil
+	case "sum", "min", "max":
+	default:
+		return fmt.Errorf("unknown reducer %q", how)
+	}
+
+	// coerce each element to the target type, then combine numerically
+	acc := reflect.New(vto.Type()).Elem()
+	for j := 0; j < n; j++ {
+		elem := reflect.New(vto.Type()).Elem()
+		if err := d.unmarshall(elem, vfrom.Index(j)); err != nil {
+			return err
+		}
+		if j == 0 {
+			acc.Set(elem)
+			continue
+		}
+		if err := d.combine(acc, elem, how); err != nil {
+			return err
+		}
+	}
+	vto.Set(acc)
+	return nil
+}
+
+// combine folds numeric value elem into acc by sum, min or max.  Sums out of
+// range of acc are an error unless the Decoder has Saturate set.
+func (d *Decoder) combine(acc reflect.Value, elem reflect.Value, how string) error {
+	switch acc.Kind() {
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		a, e := acc.Int(), elem.Int()
+		switch {
+		case how == "sum":
+			if sum := a + e; (sum > a) == (e > 0) {
+				return d.setInt(acc, sum)
+			}
+			// int64 wraparound
+			return d.setIntFromFloat(acc, float64(a)+float64(e))
+		case how == "min" && e < a, how == "max" && e > a:
+			acc.SetInt(e)
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		a, e := acc.Uint(), elem.Uint()
+		switch {
+		case how == "sum":
+			if sum := a + e; sum >= a {
+				return d.setUint(acc, sum)
+			}
+			return d.setUintFromFloat(acc, float64(a)+float64(e))
+		case how == "min" && e < a, how == "max" && e > a:
+			acc.SetUint(e)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		a, e := acc.Float(), elem.Float()
+		switch {
+		case how == "sum":
+			return d.setFloat(acc, a+e)
+		case how == "min" && e < a, how == "max" && e > a:
+			acc.SetFloat(e)
+		}
+
+	default:
+		return errorf(ErrUnsupportedConversion, "can't %s values of type %v", how, acc.Type())
+	}
+	return nil
+}
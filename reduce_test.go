@@ -0,0 +1,67 @@
+package coerce
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_Struct_reduce(t *testing.T) {
+	type x struct {
+		Delay   time.Duration `coerce:",reduce=sum"`
+		Largest int           `coerce:",reduce=max"`
+		Least   float64       `coerce:",reduce=min"`
+		First   string        `coerce:",reduce=first"`
+		Last    string        `coerce:",reduce=last"`
+		Names   string        `coerce:",reduce=join,sep=;"`
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"--delay":   []string{"1s", "250ms"},
+		"--largest": []interface{}{"3", 12.0, "1k"},
+		"--least":   []string{"2.5", "-1", "7"},
+		"--first":   []string{"a", "b"},
+		"--last":    []string{"a", "b"},
+		"--names":   []interface{}{"a", 2, true},
+	}, "--%s")
+
+	expected := x{1250 * time.Millisecond, 1024, -1, "a", "b", "a;2;true"}
+	report(err, expected, myx, t)
+
+	var bad struct {
+		Tags string `coerce:",reduce=sum"`
+	}
+	if err := Struct(&bad, map[string]interface{}{"tags": []string{"a", "b"}}); err == nil {
+		t.Errorf("expected error summing strings")
+	}
+}
+
+func Test_Struct_reduce_overflow(t *testing.T) {
+	type x struct {
+		Small int8   `coerce:",reduce=sum"`
+		Count uint8  `coerce:",reduce=sum"`
+		Big   int64  `coerce:",reduce=sum"`
+		Huge  uint64 `coerce:",reduce=sum"`
+	}
+	from := map[string]interface{}{
+		"small": []int{100, 100},
+		"count": []int{200, 100},
+		"big":   []int64{math.MaxInt64, 1},
+		"huge":  []uint64{math.MaxUint64, 1},
+	}
+
+	for key, v := range from {
+		var myx x
+		err := Struct(&myx, map[string]interface{}{key: v})
+		if !errors.Is(err, ErrOverflow) {
+			t.Errorf("%s: expected ErrOverflow, got %v", key, err)
+		}
+	}
+
+	var myx x
+	err := NewDecoder(Saturate()).Decode(&myx, from)
+	expected := x{math.MaxInt8, math.MaxUint8, math.MaxInt64, math.MaxUint64}
+	report(err, expected, myx, t)
+}
@@ -0,0 +1,86 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Redacted replaces the value of fields tagged `coerce:",secret"` in error
+// messages and in the output of Map and Fields, eg
+//
+//	Token string `coerce:",secret"`
+//
+// so that credentials don't leak into logs via coercion failures.
+const Redacted = "<redacted>"
+
+// isSecret reports whether struct field f is tagged as secret, under the
+// Decoder's TagName
+func (d *Decoder) isSecret(f reflect.StructField) bool {
+	_, ok := d.parseTag(f).option("secret")
+	return ok
+}
+
+// secretsKey is the secretTypes cache key: the type and the tag read
+type secretsKey struct {
+	t       reflect.Type
+	tagName string
+}
+
+// secretTypes caches hasSecrets by secretsKey
+var secretTypes sync.Map
+
+// hasSecrets reports whether values of type t may hold secret fields, in
+// which case the source value of a failed field can't be shown either
+func (d *Decoder) hasSecrets(t reflect.Type) bool {
+	key := secretsKey{t, d.tagName}
+	if v, ok := secretTypes.Load(key); ok {
+		return v.(bool)
+	}
+	found := d.findSecrets(t, map[reflect.Type]bool{})
+	secretTypes.Store(key, found)
+	return found
+}
+
+// findSecrets does the work for hasSecrets, skipping types already in
+// visited so that recursive types terminate. Answers for inner types may be
+// cut short by a cycle, so only the outermost answer is cached.
+func (d *Decoder) findSecrets(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if v, ok := secretTypes.Load(secretsKey{t, d.tagName}); ok {
+		return v.(bool)
+	}
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return d.findSecrets(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if d.isSecret(f) || d.findSecrets(f.Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package coerce
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Struct_secret(t *testing.T) {
+	type creds struct {
+		User  string
+		Token int `coerce:",secret"`
+	}
+	type x struct {
+		Login creds
+		Port  int
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"login": json.RawMessage(`{"user": "alice", "token": "hunter2"}`),
+		"port":  "hunter3",
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("secret leaked into error: %v", err)
+	}
+	if !strings.Contains(err.Error(), `field Login.Token (int) from key "token": can't coerce <redacted> (string): invalid value`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !strings.Contains(err.Error(), `"hunter3"`) {
+		t.Errorf("expected non-secret value in error: %v", err)
+	}
+}
+
+func Test_Fields_secret(t *testing.T) {
+	type x struct {
+		User  string
+		Token string `coerce:",secret"`
+	}
+
+	fields, err := Fields(x{"alice", "hunter2"})
+
+	expected := []KeyValue{{"User", "alice"}, {"Token", Redacted}}
+	report(err, expected, fields, t)
+}
+
+type secretList struct {
+	Next *secretList
+	Tok  string `coerce:",secret"`
+}
+
+type secretChain struct {
+	Next *secretChain
+	Tok  string `coerce:",secret"`
+}
+
+func Test_hasSecrets_recursive(t *testing.T) {
+	if !defaultDecoder.hasSecrets(reflect.TypeOf(secretList{})) {
+		t.Errorf("expected secretList to hold secrets")
+	}
+	if !defaultDecoder.hasSecrets(reflect.TypeOf(&secretList{})) {
+		t.Errorf("expected *secretList to hold secrets")
+	}
+
+	type x struct {
+		P *secretChain
+	}
+	if !defaultDecoder.hasSecrets(reflect.TypeOf(secretChain{})) {
+		t.Errorf("expected secretChain to hold secrets")
+	}
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"p": map[string]interface{}{"tok": "hunter2", "next": 7},
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("secret leaked into error: %v", err)
+	}
+}
+
+func Test_Decoder_TagName_secret(t *testing.T) {
+	type creds struct {
+		User     string
+		Password int `cfg:",secret"`
+	}
+	type x struct {
+		Login *creds
+	}
+
+	var myx x
+	err := NewDecoder(TagName("cfg")).Decode(&myx, map[string]interface{}{
+		"login": map[string]interface{}{"user": "alice", "password": "hunter2"},
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("secret leaked into error: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+var (
+	scannerType   = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// acceptsNull reports whether t is a sql.Scanner (including the sql.Null*
+// types), which nil values set to NULL rather than leaving unchanged
+func acceptsNull(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(scannerType)
+}
+
+// isSQLNull reports whether t is one of database/sql's nullable types,
+// such as sql.NullString or sql.Null[T]: a struct of the value and a Valid
+// flag
+func isSQLNull(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" &&
+		strings.HasPrefix(t.Name(), "Null") && t.NumField() == 2 &&
+		t.Field(1).Name == "Valid" && t.Field(1).Type.Kind() == reflect.Bool
+}
+
+// scanner returns vto as a sql.Scanner, if it implements one
+func scanner(vto reflect.Value) (sql.Scanner, bool) {
+	if !vto.CanAddr() {
+		return nil, false
+	}
+	s, ok := vto.Addr().Interface().(sql.Scanner)
+	return s, ok
+}
+
+// unmarshallNull coerces non-nil vfrom into the value of sql.Null* vto,
+// and marks it valid
+func (d *Decoder) unmarshallNull(vto reflect.Value, vfrom reflect.Value) error {
+	v := reflect.New(vto.Type()).Elem()
+	if err := d.unmarshall(v.Field(0), vfrom); err != nil {
+		return err
+	}
+	v.Field(1).SetBool(true)
+	vto.Set(v)
+	return nil
+}
+
+// setNull stores SQL NULL in vto if it is a sql.Scanner (which includes
+// the sql.Null* types), for nil sources; other targets are left alone
+func setNull(vto reflect.Value) error {
+	if s, ok := scanner(vto); ok {
+		return s.Scan(nil)
+	}
+	return nil
+}
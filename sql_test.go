@@ -0,0 +1,81 @@
+package coerce
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// csv is a sql.Scanner of comma-separated strings
+type csv []string
+
+func (c *csv) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*c = nil
+	case string:
+		*c = strings.Split(src, ",")
+	case []byte:
+		*c = strings.Split(string(src), ",")
+	default:
+		return fmt.Errorf("can't scan %T into csv", src)
+	}
+	return nil
+}
+
+func Test_Struct_sql_null(t *testing.T) {
+	type row struct {
+		Name    sql.NullString
+		Age     sql.NullInt64
+		Admin   sql.NullBool
+		Score   sql.NullFloat64
+		Joined  sql.NullTime
+		Quota   sql.Null[uint16]
+		Tags    csv
+		Missing sql.NullString
+	}
+
+	myrow := row{
+		Name:    sql.NullString{String: "old", Valid: true},
+		Tags:    csv{"old"},
+		Missing: sql.NullString{String: "kept", Valid: true},
+	}
+	err := Struct(&myrow, map[string]interface{}{
+		"name":   nil,
+		"age":    "42",
+		"admin":  "yes",
+		"score":  9.5,
+		"joined": "2024-01-02",
+		"quota":  "1k",
+		"tags":   []byte("a,b"),
+	})
+	expected := row{
+		Age:     sql.NullInt64{Int64: 42, Valid: true},
+		Admin:   sql.NullBool{Bool: true, Valid: true},
+		Score:   sql.NullFloat64{Float64: 9.5, Valid: true},
+		Joined:  sql.NullTime{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+		Quota:   sql.Null[uint16]{V: 1024, Valid: true},
+		Tags:    csv{"a", "b"},
+		Missing: sql.NullString{String: "kept", Valid: true},
+	}
+	report(err, expected, myrow, t)
+
+	// nil scans as NULL
+	err = Struct(&myrow, map[string]interface{}{"tags": nil, "age": nil})
+	expected.Tags, expected.Age = nil, sql.NullInt64{}
+	report(err, expected, myrow, t)
+
+	var ns sql.NullString
+	err = Var(&ns, 12)
+	report(err, sql.NullString{String: "12", Valid: true}, ns, t)
+
+	var ni sql.NullInt32
+	if err := Var(&ni, "many"); err == nil {
+		t.Errorf("expected error for a bad value")
+	}
+	if err := Var(&myrow.Tags, 5); err == nil {
+		t.Errorf("expected error from Scan")
+	}
+}
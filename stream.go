@@ -0,0 +1,78 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Stream decodes each map received from 'in' into a new T (which must be a
+// struct type) using a Coercer, compiled once by a Decoder configured by
+// opts.  Decoded values are sent on the first returned channel; records
+// which fail to decode are dropped and their errors, prefixed with the
+// record's index, are sent on the second.  Both channels are closed once
+// 'in' is closed and drained, or ctx is done, and callers must keep
+// receiving from both until then (or cancel ctx).
+func Stream[T any](ctx context.Context, in <-chan map[string]interface{}, opts ...Option) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errs := make(chan error)
+	c, cerr := NewDecoder(opts...).Compile(reflect.TypeOf((*T)(nil)).Elem())
+
+	go func() {
+		defer close(out)
+		defer close(errs)
+
+		for i := 0; ; i++ {
+			var m map[string]interface{}
+			var ok bool
+			select {
+			case m, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			var v T
+			err := cerr
+			if err == nil {
+				err = c.Decode(&v, m)
+			}
+			if err != nil {
+				select {
+				case errs <- fmt.Errorf("record %d: %w", i, err):
+				case <-ctx.Done():
+					return
+				}
+			} else {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, errs
+}
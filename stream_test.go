@@ -0,0 +1,77 @@
+package coerce
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func Test_Stream(t *testing.T) {
+	type rec struct {
+		ID   int
+		Name string
+	}
+
+	in := make(chan map[string]interface{})
+	go func() {
+		in <- map[string]interface{}{"id": "1", "name": "one"}
+		in <- map[string]interface{}{"id": "x"}
+		in <- map[string]interface{}{"id": 3.0, "name": "three"}
+		close(in)
+	}()
+
+	out, errs := Stream[rec](context.Background(), in)
+
+	var got []rec
+	var errCount int
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range errs {
+			errCount++
+		}
+	}()
+	for r := range out {
+		got = append(got, r)
+	}
+	wg.Wait()
+
+	report(nil, []rec{{1, "one"}, {3, "three"}}, got, t)
+	if errCount != 1 {
+		t.Errorf("expected 1 error, got %d", errCount)
+	}
+}
+
+func Test_Stream_cancel(t *testing.T) {
+	type rec struct {
+		ID int
+	}
+
+	// an endless producer
+	in := make(chan map[string]interface{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case in <- map[string]interface{}{"id": "x"}:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out, errs := Stream[rec](ctx, in)
+	if err := <-errs; err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// stop reading: both channels close once ctx is cancelled
+	cancel()
+	for range errs {
+	}
+	for range out {
+	}
+}
@@ -0,0 +1,101 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxSuggestDistance is the greatest edit distance at which an unused key
+// is taken to be a typo of a known one
+const maxSuggestDistance = 2
+
+// unusedKeysError reports the keys which ErrorOnUnusedKeys found unused,
+// with suggestions for any which look like typos of known keys
+func (d *Decoder) unusedKeysError(t reflect.Type, unused []string, formats []string) error {
+	known := d.knownKeys(t, formats)
+	var hints []string
+	for _, k := range unused {
+		if s, ok := suggestKey(k, known); ok {
+			hints = append(hints, fmt.Sprintf("%q for %q", s, k))
+		}
+	}
+	if len(hints) == 0 {
+		return fmt.Errorf("unused keys %q", unused)
+	}
+	return fmt.Errorf("unused keys %q (did you mean %s?)", unused, strings.Join(hints, ", "))
+}
+
+// knownKeys lists the keys which the fields of struct type t would match
+func (d *Decoder) knownKeys(t reflect.Type, formats []string) []string {
+	var known []string
+	nextPos := 0
+	for _, fl := range d.fields(t) {
+		if _, ok := fl.tag.position(&nextPos); ok {
+			continue
+		}
+		switch {
+		case fl.tag.name != "":
+			known = append(known, fl.tag.name)
+		case d.keyNames != nil:
+			known = append(known, d.keyNames(fl.Name)...)
+		default:
+			known = append(known, candidateKeys(fl.Name, formats)...)
+		}
+		known = append(known, fl.tag.aliases...)
+	}
+	return known
+}
+
+// suggestKey returns the key in known nearest to k, if within
+// maxSuggestDistance edits
+func suggestKey(k string, known []string) (string, bool) {
+	best, bestDist := "", maxSuggestDistance+1
+	for _, c := range known {
+		if dist := editDistance(k, c); dist < bestDist {
+			best, bestDist = c, dist
+		}
+	}
+	return best, bestDist <= maxSuggestDistance
+}
+
+// editDistance returns the Levenshtein distance between a and b, in runes
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
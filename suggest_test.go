@@ -0,0 +1,64 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_editDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"--intslice", "--intslise", 1},
+		{"--intslice", "--intsilce", 2},
+		{"héllo", "hello", 1},
+	} {
+		report(nil, tc.want, editDistance(tc.a, tc.b), t)
+	}
+}
+
+func Test_unused_key_suggestions(t *testing.T) {
+	type x struct {
+		Intslice []int
+		Verbose  bool   `coerce:"--verbose"`
+		Timeout  int    `coerce:",alias=--ttl"`
+		File     string `coerce:",pos"`
+	}
+	d := NewDecoder(Formats("--%s"), ErrorOnUnusedKeys())
+	var myx x
+	err := d.Decode(&myx, map[string]interface{}{
+		"--intslise": "1",
+		"--verbse":   true,
+		"--tll":      1,
+		"--zzzzzz":   1,
+	})
+	report(nil, `unused keys ["--intslise" "--tll" "--verbse" "--zzzzzz"] `+
+		`(did you mean "--intslice" for "--intslise", "--ttl" for "--tll", "--verbose" for "--verbse"?)`,
+		fmt.Sprint(err), t)
+
+	err = d.Decode(&myx, map[string]interface{}{"--zzzzzz": 1})
+	report(nil, `unused keys ["--zzzzzz"]`, fmt.Sprint(err), t)
+}
@@ -0,0 +1,107 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tagName is the struct tag key read by coerce
+const tagName = "coerce"
+
+// fieldTag holds the parsed contents of a `coerce:"name,opt,key=value"`
+// struct tag
+type fieldTag struct {
+	name    string
+	options map[string]string
+	skip    bool     // tagged "-", as opposed to "-," for key "-"
+	aliases []string // from `alias=` options, in order
+}
+
+// parseTag parses the coerce tag of struct field f
+func parseTag(f reflect.StructField) fieldTag {
+	return parseTagKey(f, tagName)
+}
+
+// parseTag parses the tag of struct field f under the Decoder's TagName,
+// or if there is none, takes the key name from the first of its
+// TagFallback tags present
+func (d *Decoder) parseTag(f reflect.StructField) fieldTag {
+	key := tagName
+	if d.tagName != "" {
+		key = d.tagName
+	}
+	if _, ok := f.Tag.Lookup(key); !ok {
+		for _, fallback := range d.tagFallback {
+			if v, ok := f.Tag.Lookup(fallback); ok {
+				name, _, _ := strings.Cut(v, ",")
+				return fieldTag{name: name, options: map[string]string{}, skip: v == "-"}
+			}
+		}
+	}
+	return parseTagKey(f, key)
+}
+
+// parseTagKey parses the struct tag held under key in field f
+func parseTagKey(f reflect.StructField, key string) fieldTag {
+	tag := f.Tag.Get(key)
+	parts := strings.Split(tag, ",")
+	t := fieldTag{name: parts[0], options: map[string]string{}, skip: tag == "-"}
+	for _, opt := range parts[1:] {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			continue
+		}
+		key, value := opt, ""
+		if eq := strings.Index(opt, "="); eq >= 0 {
+			key, value = opt[:eq], opt[eq+1:]
+		}
+		if key == "alias" {
+			t.aliases = append(t.aliases, value)
+		}
+		t.options[key] = value
+	}
+	return t
+}
+
+// option returns the value of tag option key, and whether it was present
+func (t fieldTag) option(key string) (string, bool) {
+	v, ok := t.options[key]
+	return v, ok
+}
+
+// position returns the index given by a `pos=N` tag option.  A bare `pos`
+// takes the index *next; either way *next is advanced past the field.
+func (t fieldTag) position(next *int) (int, bool) {
+	v, ok := t.option("pos")
+	if !ok {
+		return 0, false
+	}
+	n := *next
+	if v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i >= 0 {
+			n = i
+		}
+	}
+	*next = n + 1
+	return n, true
+}
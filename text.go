@@ -0,0 +1,54 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"encoding"
+	"reflect"
+)
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// textUnmarshaler returns vto (or its address) as an
+// encoding.TextUnmarshaler, if its type implements that interface
+func textUnmarshaler(vto reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if vto.CanAddr() {
+		if u, ok := vto.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u, true
+		}
+	}
+	if vto.Kind() == reflect.Ptr && vto.IsNil() {
+		return nil, false
+	}
+	u, ok := vto.Interface().(encoding.TextUnmarshaler)
+	return u, ok
+}
+
+// textSource returns the content of vfrom if it is a string or a plain
+// []byte
+func textSource(vfrom reflect.Value) (string, bool) {
+	switch {
+	case vfrom.Kind() == reflect.String:
+		return vfrom.String(), true
+	case vfrom.Type() == bytesType:
+		return string(vfrom.Bytes()), true
+	}
+	return "", false
+}
@@ -0,0 +1,51 @@
+package coerce
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type level string
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch s := strings.ToLower(string(text)); s {
+	case "debug", "info", "error":
+		*l = level(s)
+		return nil
+	}
+	return fmt.Errorf("unknown level %q", text)
+}
+
+type point struct {
+	X, Y int
+}
+
+func (p *point) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d,%d", &p.X, &p.Y)
+	return err
+}
+
+func Test_TextUnmarshaler(t *testing.T) {
+	type x struct {
+		Level  level
+		Origin point
+		Path   []point
+		Maybe  *point
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"level":  "INFO",
+		"origin": []byte("1,2"),
+		"path":   []string{"3,4", "5,6"},
+		"maybe":  "7,8",
+	})
+
+	expected := x{"info", point{1, 2}, []point{{3, 4}, {5, 6}}, &point{7, 8}}
+	report(err, expected, myx, t)
+
+	if err := Var(&myx.Level, "loud"); err == nil {
+		t.Errorf("expected error for unknown level")
+	}
+}
@@ -0,0 +1,255 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	timeLayoutsMu sync.RWMutex
+	timeLayouts   []string // registered via RegisterTimeLayouts
+)
+
+// RegisterTimeLayouts adds layouts to those tried, before the defaults,
+// by every Decoder when parsing strings into time.Time values.  Layouts
+// may be custom layout strings or the names of time package constants
+// such as "RFC1123".
+func RegisterTimeLayouts(layouts ...string) {
+	timeLayoutsMu.Lock()
+	defer timeLayoutsMu.Unlock()
+	timeLayouts = append(timeLayouts, layouts...)
+}
+
+// defaultTimeLayouts are tried in order when parsing strings into
+// time.Time values
+var defaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST", // time.Time.String()
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.UnixDate,
+	time.ANSIC,
+}
+
+// namedLayouts maps the names of the time package's layout constants, for
+// use in `layout=` tags
+var namedLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// formatTime renders t according to layout, which may be the name of a
+// time package layout constant (eg "RFC3339"), one of "unix", "unixmilli"
+// or "unixnano" for an int64 epoch, or a custom layout string.  An empty
+// layout returns t unchanged.
+func formatTime(t time.Time, layout string) interface{} {
+	switch strings.ToLower(layout) {
+	case "":
+		return t
+	case "unix", "epoch":
+		return t.Unix()
+	case "unixmilli":
+		return t.UnixMilli()
+	case "unixnano":
+		return t.UnixNano()
+	}
+	if named, ok := namedLayouts[layout]; ok {
+		layout = named
+	}
+	return t.Format(layout)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// epochLayouts maps the `layout=` tag values for integer epochs to their
+// units
+var epochLayouts = map[string]time.Duration{
+	"unix":      time.Second,
+	"epoch":     time.Second,
+	"unixmilli": time.Millisecond,
+	"unixnano":  time.Nanosecond,
+}
+
+// isTime reports whether t is time.Time or *time.Time
+func isTime(t reflect.Type) bool {
+	return t == timeType || t.Kind() == reflect.Ptr && t.Elem() == timeType
+}
+
+// unmarshallLayout sets time.Time (or *time.Time) vto from vfrom as per a
+// `layout=` tag: strings must match layout, which is named as per
+// formatTime, and epoch layouts take numbers (or numeric strings) in their
+// unit.  Other values are coerced as usual.
+func (d *Decoder) unmarshallLayout(vto reflect.Value, vfrom reflect.Value, layout string) error {
+	if vto.Kind() == reflect.Ptr {
+		if vto.IsNil() {
+			vto.Set(reflect.New(timeType))
+		}
+		vto = vto.Elem()
+	}
+
+	if unit, ok := epochLayouts[strings.ToLower(layout)]; ok {
+		if vfrom.Kind() == reflect.String {
+			n, err := strconv.ParseInt(strings.TrimSpace(vfrom.String()), 10, 64)
+			if err != nil {
+				return parseError(err)
+			}
+			vfrom = reflect.ValueOf(n)
+		}
+		if isNumber(vfrom.Kind()) {
+			return d.unmarshallEpoch(vto, vfrom, unit)
+		}
+	} else if vfrom.Kind() == reflect.String {
+		if named, ok := namedLayouts[layout]; ok {
+			layout = named
+		}
+		loc := d.location
+		if loc == nil {
+			loc = time.UTC
+		}
+		t, err := time.ParseInLocation(layout, strings.TrimSpace(vfrom.String()), loc)
+		if err != nil {
+			return err
+		}
+		vto.Set(reflect.ValueOf(t))
+		return nil
+	}
+	return d.unmarshall(vto, vfrom)
+}
+
+// unmarshallEpoch sets time.Time vto from numeric vfrom, taken as a Unix
+// timestamp in the given unit (seconds if unset)
+func (d *Decoder) unmarshallEpoch(vto reflect.Value, vfrom reflect.Value, unit time.Duration) error {
+	if unit <= 0 {
+		unit = time.Second
+	}
+	loc := d.location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var t time.Time
+	switch {
+	case isFloat(vfrom.Kind()):
+		f := vfrom.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return errorf(ErrInvalidValue, "can't use %v as a timestamp", f)
+		}
+		n, frac := math.Modf(f)
+		if n < math.MinInt64 || n >= math.MaxInt64 {
+			return errorf(ErrOverflow, "timestamp %v out of range", f)
+		}
+		t = epoch(int64(n), unit).Add(time.Duration(math.Round(frac * float64(unit))))
+	case vfrom.Kind() >= reflect.Uint && vfrom.Kind() <= reflect.Uintptr:
+		if vfrom.Uint() > math.MaxInt64 {
+			return errorf(ErrOverflow, "timestamp %d out of range", vfrom.Uint())
+		}
+		t = epoch(int64(vfrom.Uint()), unit)
+	default:
+		t = epoch(vfrom.Int(), unit)
+	}
+	vto.Set(reflect.ValueOf(t.In(loc)))
+	return nil
+}
+
+// epoch returns the time n units after the Unix epoch
+func epoch(n int64, unit time.Duration) time.Time {
+	per := int64(time.Second / unit)
+	if per <= 1 {
+		return time.Unix(n*int64(unit/time.Second), 0)
+	}
+	return time.Unix(n/per, n%per*int64(unit))
+}
+
+// parseTime parses s using the first layout which fits, trying the
+// Decoder's TimeLayouts, then any registered via RegisterTimeLayouts, then
+// the defaults.  Timestamps without zone information are taken to be in
+// the Decoder's location (UTC unless set via the Location option).
+func (d *Decoder) parseTime(s string) (time.Time, error) {
+	loc := d.location
+	if loc == nil {
+		loc = time.UTC
+	}
+	s = strings.TrimSpace(s)
+
+	timeLayoutsMu.RLock()
+	registered := timeLayouts
+	timeLayoutsMu.RUnlock()
+
+	for _, layouts := range [][]string{d.timeLayouts, registered, defaultTimeLayouts} {
+		for _, layout := range layouts {
+			if named, ok := namedLayouts[layout]; ok {
+				layout = named
+			}
+			if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, errorf(ErrInvalidValue, "can't parse %q as a time", s)
+}
+
+// dayWeekRE matches the day and week components of a duration
+var dayWeekRE = regexp.MustCompile(`(\d*\.?\d+)([dw])`)
+
+// parseDuration is time.ParseDuration extended with days ("d", 24h) and
+// weeks ("w", 7d), eg "2d", "1w" or "1d12h"
+func parseDuration(s string) (time.Duration, error) {
+	if strings.ContainsAny(s, "dw") {
+		hours := dayWeekRE.ReplaceAllStringFunc(s, func(m string) string {
+			n, unit := m[:len(m)-1], m[len(m)-1]
+			f, _ := strconv.ParseFloat(n, 64)
+			if unit == 'w' {
+				f *= 7
+			}
+			return strconv.FormatFloat(f*24, 'f', -1, 64) + "h"
+		})
+		if d, err := time.ParseDuration(hours); err == nil {
+			return d, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
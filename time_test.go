@@ -0,0 +1,145 @@
+package coerce
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Decoder_Location(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	var tm time.Time
+	err = NewDecoder(Location(ny)).Var(&tm, "2024-01-02 15:04")
+	report(err, time.Date(2024, 1, 2, 15, 4, 0, 0, ny), tm, t)
+
+	// explicit zones win over the default location
+	err = NewDecoder(Location(ny)).Var(&tm, "2024-01-02T15:04:00Z")
+	report(err, time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC), tm, t)
+
+	err = Var(&tm, "2024-01-02 15:04")
+	report(err, time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC), tm, t)
+}
+
+func Test_TimeLayouts(t *testing.T) {
+	var tm time.Time
+	if err := Var(&tm, "02/01/2024"); err == nil {
+		t.Errorf("expected error without a matching layout")
+	}
+
+	err := NewDecoder(TimeLayouts("02/01/2006", "RubyDate")).Var(&tm, "02/01/2024")
+	report(err, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), tm, t)
+
+	err = NewDecoder(TimeLayouts("RubyDate")).Var(&tm, "Tue Jan 02 15:04:05 +0000 2024")
+	report(err, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), tm, t)
+
+	RegisterTimeLayouts("20060102")
+	defer func() { timeLayouts = nil }()
+	err = Var(&tm, "20240102")
+	report(err, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), tm, t)
+}
+
+func Test_epoch_time(t *testing.T) {
+	type x struct {
+		Created time.Time
+		Updated time.Time
+	}
+
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"created": int64(1704207845),
+		"updated": 1704207845.25, // as decoded from JSON
+	})
+	expected := x{
+		Created: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		Updated: time.Date(2024, 1, 2, 15, 4, 5, 250000000, time.UTC),
+	}
+	report(err, expected, myx, t)
+
+	var tm time.Time
+	err = NewDecoder(EpochUnit(time.Millisecond)).Var(&tm, uint64(1704207845123))
+	report(err, time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC), tm, t)
+
+	err = NewDecoder(EpochUnit(time.Millisecond)).Var(&tm, -1500)
+	report(err, time.Date(1969, 12, 31, 23, 59, 58, 500000000, time.UTC), tm, t)
+}
+
+func Test_Struct_time_layout(t *testing.T) {
+	type x struct {
+		Day     time.Time  `coerce:",layout=02/01/2006"`
+		Created time.Time  `coerce:",layout=RFC3339"`
+		Expires time.Time  `coerce:",layout=unixmilli"`
+		Seen    *time.Time `coerce:",layout=unix"`
+		Plain   time.Time
+	}
+
+	seen := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	var myx x
+	err := Struct(&myx, map[string]interface{}{
+		"day":     "02/01/2024",
+		"created": "2024-01-02T15:04:05Z",
+		"expires": "1704207845123",
+		"seen":    1704207845,
+		"plain":   "2024-01-02",
+	})
+	expected := x{
+		Day:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Created: seen,
+		Expires: time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC),
+		Seen:    &seen,
+		Plain:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	report(err, expected, myx, t)
+
+	// the layout replaces the defaults, and is not itself a default
+	type y struct {
+		Day   time.Time `coerce:",layout=02/01/2006"`
+		Other time.Time
+	}
+	var myy y
+	if err := Struct(&myy, map[string]interface{}{"day": "2024-01-02"}); err == nil {
+		t.Errorf("expected error for a value not matching the layout")
+	}
+	if err := Struct(&myy, map[string]interface{}{"other": "02/01/2024"}); err == nil {
+		t.Errorf("expected error for a layout used by another field")
+	}
+
+	// Map output can be passed back
+	type z struct {
+		Created time.Time `coerce:",layout=RFC3339"`
+		Expires time.Time `coerce:",layout=unix"`
+		Day     time.Time `coerce:",layout=2006-01-02"`
+	}
+	in := z{seen, seen, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	m, err := Map(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out z
+	err = Struct(&out, m)
+	report(err, in, out, t)
+}
+
+func Test_Duration_days_weeks(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"90s":      90 * time.Second,
+		"2d":       48 * time.Hour,
+		"1w":       7 * 24 * time.Hour,
+		"1d12h":    36 * time.Hour,
+		"1.5d":     36 * time.Hour,
+		"-1w1d":    -8 * 24 * time.Hour,
+		"2w3d4h5m": (17*24+4)*time.Hour + 5*time.Minute,
+	} {
+		var d time.Duration
+		err := Var(&d, s)
+		report(err, want, d, t)
+	}
+	var d time.Duration
+	for _, s := range []string{"d", "2x", "1dd"} {
+		if err := Var(&d, s); err == nil {
+			t.Errorf("expected error for %q, got %v", s, d)
+		}
+	}
+}
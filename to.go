@@ -0,0 +1,52 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import "fmt"
+
+// To coerces 'from' into a value of type T, eg
+//
+//	port, err := coerce.To[uint16]("8080")
+//
+// as a typed alternative to Var and the Int, Float64 etc helpers.  As with
+// a nil map value in Struct, a nil 'from' leaves the result at its zero value.
+func To[T any](from interface{}) (T, error) {
+	var v T
+	if from == nil {
+		return v, nil
+	}
+	err := Var(&v, from)
+	return v, err
+}
+
+// Get coerces the value under key in 'm' into a value of type T.  It is an
+// error if the key is not present.
+func Get[T any](m map[string]interface{}, key string) (T, error) {
+	from, ok := m[key]
+	if !ok {
+		var zero T
+		return zero, errorf(ErrKeyNotFound, "key %q not found in map", key)
+	}
+	v, err := To[T](from)
+	if err != nil {
+		return v, fmt.Errorf("key %q: %w", key, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,38 @@
+package coerce
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_To(t *testing.T) {
+	port, err := To[uint16]("8080")
+	report(err, uint16(8080), port, t)
+
+	d, err := To[time.Duration]("1m")
+	report(err, time.Minute, d, t)
+
+	if _, err := To[int8]("300"); err == nil {
+		t.Errorf("expected overflow error")
+	}
+
+	n, err := To[int](nil)
+	report(err, 0, n, t)
+}
+
+func Test_Get(t *testing.T) {
+	m := map[string]interface{}{"retries": "3", "ratio": "oops", "unset": nil}
+
+	n, err := Get[int](m, "retries")
+	report(err, 3, n, t)
+
+	n, err = Get[int](m, "unset")
+	report(err, 0, n, t)
+
+	if _, err := Get[float64](m, "ratio"); err == nil {
+		t.Errorf("expected coercion error")
+	}
+	if _, err := Get[string](m, "missing"); err == nil {
+		t.Errorf("expected error for missing key")
+	}
+}
@@ -0,0 +1,169 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// union describes how to decode maps into an interface type: the value
+// under key names the concrete type to decode into
+type union struct {
+	key      string
+	variants map[string]reflect.Type
+}
+
+// Union registers a discriminated union with the Decoder: a map decoded
+// into a field of interface type iface has its 'key' entry looked up in
+// variants to choose the concrete type (a struct, or pointer to struct,
+// implementing iface) into which its remaining entries are decoded.
+// For example, with
+//
+//	coerce.Union(reflect.TypeOf((*Shape)(nil)).Elem(), "kind",
+//		map[string]reflect.Type{
+//			"circle": reflect.TypeOf(Circle{}),
+//			"square": reflect.TypeOf(&Square{}),
+//		})
+//
+// a []Shape field can be decoded from a list of maps such as
+// {"kind": "circle", "radius": 2}.
+func Union(iface reflect.Type, key string, variants map[string]reflect.Type) Option {
+	return func(d *Decoder) {
+		if d.unions == nil {
+			d.unions = map[reflect.Type]union{}
+		}
+		d.unions[iface] = union{key: key, variants: variants}
+	}
+}
+
+var (
+	unionsMu sync.RWMutex
+	unions   = map[reflect.Type]union{}
+)
+
+// RegisterInterface registers a discriminated union, as per Union, for
+// every Decoder: maps decoded into fields of interface type T choose their
+// concrete type by their 'key' entry, eg
+//
+//	coerce.RegisterInterface[Backend]("type", map[string]reflect.Type{
+//		"s3":   reflect.TypeOf(&S3Backend{}),
+//		"file": reflect.TypeOf(FileBackend{}),
+//	})
+//
+// so that plugins can register their own variants from init functions.
+// Unions given to a Decoder take precedence.  Registering nil variants
+// removes the union for T.  RegisterInterface panics if T is not an
+// interface type, or any variant is not a struct (or pointer to struct)
+// implementing T.
+func RegisterInterface[T any](key string, variants map[string]reflect.Type) {
+	iface := reflect.TypeOf((*T)(nil)).Elem()
+	if err := checkVariants(iface, variants); err != nil {
+		panic("coerce: RegisterInterface: " + err.Error())
+	}
+	unionsMu.Lock()
+	defer unionsMu.Unlock()
+	if variants == nil {
+		delete(unions, iface)
+		return
+	}
+	unions[iface] = union{key: key, variants: variants}
+}
+
+// checkVariants returns an error unless iface is an interface type and
+// each of variants is a struct, or pointer to struct, implementing it
+func checkVariants(iface reflect.Type, variants map[string]reflect.Type) error {
+	if iface.Kind() != reflect.Interface {
+		return fmt.Errorf("%v is not an interface type", iface)
+	}
+	for name, vt := range variants {
+		st := vt
+		if st != nil && st.Kind() == reflect.Ptr {
+			st = st.Elem()
+		}
+		if st == nil || st.Kind() != reflect.Struct {
+			return fmt.Errorf("variant %q of %v is not a struct", name, iface)
+		}
+		if !vt.Implements(iface) {
+			return fmt.Errorf("variant %q (%v) does not implement %v", name, vt, iface)
+		}
+	}
+	return nil
+}
+
+// lookupUnion returns the Decoder's union for interface type t, or failing
+// that the registered one, if any
+func (d *Decoder) lookupUnion(t reflect.Type) (union, bool) {
+	if t.Kind() != reflect.Interface {
+		return union{}, false
+	}
+	if u, ok := d.unions[t]; ok {
+		return u, true
+	}
+	unionsMu.RLock()
+	defer unionsMu.RUnlock()
+	u, ok := unions[t]
+	return u, ok
+}
+
+// unmarshallUnion decodes map m into the variant of union u it names, and
+// stores the result in interface vto
+func (d *Decoder) unmarshallUnion(vto reflect.Value, u union, m map[string]interface{}) error {
+	tto := vto.Type()
+
+	kind, ok := m[u.key]
+	if !ok {
+		return errorf(ErrKeyNotFound, "missing discriminator %q for %v", u.key, tto)
+	}
+	vt, ok := u.variants[fmt.Sprint(kind)]
+	if !ok {
+		return errorf(ErrUnsupportedConversion, "unknown %s %q for %v", u.key, kind, tto)
+	}
+
+	rest := make(map[string]interface{}, len(m)-1)
+	for k, v := range m {
+		if k != u.key {
+			rest[k] = v
+		}
+	}
+
+	// decode into a new struct, then point to it if the variant is a pointer
+	st := vt
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return errorf(ErrUnsupportedConversion, "variant %q of %v is not a struct", kind, tto)
+	}
+	pv := reflect.New(st)
+	if err := d.unmarshallStruct(pv.Elem(), rest, nil); err != nil {
+		return err
+	}
+	result := pv.Elem()
+	if vt.Kind() == reflect.Ptr {
+		result = pv
+	}
+	if !result.Type().AssignableTo(tto) {
+		return errorf(ErrUnsupportedConversion, "variant %v does not implement %v", vt, tto)
+	}
+	vto.Set(result)
+	return nil
+}
@@ -0,0 +1,130 @@
+package coerce
+
+import (
+	"reflect"
+	"testing"
+)
+
+type shape interface {
+	area() float64
+}
+
+type circle struct {
+	Radius float64
+}
+
+func (c circle) area() float64 { return 3 * c.Radius * c.Radius }
+
+type square struct {
+	Side float64
+}
+
+func (s *square) area() float64 { return s.Side * s.Side }
+
+func Test_Decoder_Union(t *testing.T) {
+	d := NewDecoder(Union(reflect.TypeOf((*shape)(nil)).Elem(), "kind", map[string]reflect.Type{
+		"circle": reflect.TypeOf(circle{}),
+		"square": reflect.TypeOf(&square{}),
+	}))
+
+	type drawing struct {
+		Shapes []shape
+	}
+
+	var dr drawing
+	err := d.Decode(&dr, map[string]interface{}{
+		"shapes": []interface{}{
+			map[string]interface{}{"kind": "circle", "radius": "2"},
+			map[string]interface{}{"kind": "square", "side": "3"},
+		},
+	})
+	report(err, drawing{[]shape{circle{2}, &square{3}}}, dr, t)
+
+	err = d.Decode(&dr, map[string]interface{}{
+		"shapes": []interface{}{map[string]interface{}{"kind": "hexagon"}},
+	})
+	if err == nil {
+		t.Errorf("expected error for unknown kind")
+	}
+}
+
+type backend interface {
+	name() string
+}
+
+type fileBackend struct {
+	Path string
+}
+
+func (f fileBackend) name() string { return "file:" + f.Path }
+
+type s3Backend struct {
+	Bucket string
+	Region string
+}
+
+func (s *s3Backend) name() string { return "s3:" + s.Bucket }
+
+func Test_RegisterInterface(t *testing.T) {
+	RegisterInterface[backend]("type", map[string]reflect.Type{
+		"file": reflect.TypeOf(fileBackend{}),
+		"s3":   reflect.TypeOf(&s3Backend{}),
+	})
+	defer RegisterInterface[backend]("type", nil)
+
+	type config struct {
+		Primary backend
+		Mirrors map[string]backend
+	}
+
+	var c config
+	err := Struct(&c, map[string]interface{}{
+		"primary": map[string]interface{}{"type": "s3", "bucket": "logs", "region": "eu-west-1"},
+		"mirrors": map[string]interface{}{
+			"local": map[interface{}]interface{}{"type": "file", "path": "/var/logs"},
+		},
+	})
+	expected := config{
+		Primary: &s3Backend{"logs", "eu-west-1"},
+		Mirrors: map[string]backend{"local": fileBackend{"/var/logs"}},
+	}
+	report(err, expected, c, t)
+
+	// a Decoder's own unions take precedence
+	d := NewDecoder(Union(reflect.TypeOf((*backend)(nil)).Elem(), "kind", map[string]reflect.Type{
+		"file": reflect.TypeOf(fileBackend{}),
+	}))
+	var o config
+	err = d.Decode(&o, map[string]interface{}{
+		"primary": map[string]interface{}{"kind": "file", "path": "/tmp"},
+	})
+	report(err, config{Primary: fileBackend{"/tmp"}}, o, t)
+
+	if err := Struct(&c, map[string]interface{}{"primary": map[string]interface{}{"bucket": "x"}}); err == nil {
+		t.Errorf("expected error for a missing discriminator")
+	}
+}
+
+func Test_RegisterInterface_invalid(t *testing.T) {
+	for name, variants := range map[string]map[string]reflect.Type{
+		"not implemented": {"s3": reflect.TypeOf(s3Backend{})}, // pointer receiver
+		"not a struct":    {"n": reflect.TypeOf(0)},
+		"nil type":        {"nil": nil},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			RegisterInterface[backend]("type", variants)
+		}()
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for a non-interface type")
+		}
+	}()
+	RegisterInterface[fileBackend]("type", map[string]reflect.Type{})
+}
@@ -0,0 +1,60 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+)
+
+// isUUIDType reports whether t is shaped like the common UUID types
+// (github.com/google/uuid, github.com/gofrs/uuid, etc), all of which are
+// [16]byte underneath
+func isUUIDType(t reflect.Type) bool {
+	return t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8
+}
+
+// parseUUID parses the canonical 36-character form as well as the
+// 32-digit, braced and "urn:uuid:" variants
+func parseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+
+	h := strings.TrimSpace(s)
+	if len(h) > 9 && strings.EqualFold(h[:9], "urn:uuid:") {
+		h = h[9:]
+	}
+	if len(h) == 38 && h[0] == '{' && h[37] == '}' {
+		h = h[1:37]
+	}
+	if len(h) == 36 {
+		if h[8] != '-' || h[13] != '-' || h[18] != '-' || h[23] != '-' {
+			return u, errorf(ErrInvalidValue, "invalid UUID %q", s)
+		}
+		h = h[:8] + h[9:13] + h[14:18] + h[19:23] + h[24:]
+	}
+	if len(h) != 32 {
+		return u, errorf(ErrInvalidValue, "invalid UUID %q", s)
+	}
+	if _, err := hex.Decode(u[:], []byte(h)); err != nil {
+		return u, errorf(ErrInvalidValue, "invalid UUID %q", s)
+	}
+	return u, nil
+}
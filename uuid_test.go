@@ -0,0 +1,25 @@
+package coerce
+
+import "testing"
+
+type testUUID [16]byte
+
+func Test_UUID(t *testing.T) {
+	expected := testUUID{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+
+	for _, s := range []string{
+		"123e4567-e89b-12d3-a456-426614174000",
+		"{123E4567-E89B-12D3-A456-426614174000}",
+		"urn:uuid:123e4567-e89b-12d3-a456-426614174000",
+		"123e4567e89b12d3a456426614174000",
+	} {
+		var u testUUID
+		err := Var(&u, s)
+		report(err, expected, u, t)
+	}
+
+	var u testUUID
+	if err := Var(&u, "123e4567-e89b-12d3-a456-42661417400z"); err == nil {
+		t.Errorf("expected error for invalid UUID")
+	}
+}
@@ -0,0 +1,55 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+)
+
+// maxFormMemory is the memory limit for multipart forms parsed by Request;
+// larger files are stored on disk as per http.Request.ParseMultipartForm
+const maxFormMemory = 32 << 20
+
+// Values coerces url.Values (eg query parameters or a parsed form) into
+// the fields of the structure pointed to by 'to', as per Struct.
+// Parameters given more than once fill slice fields; a single value fills
+// a scalar field.
+func Values(to interface{}, values url.Values, formats ...string) error {
+	m := make(map[string]interface{}, len(values))
+	for k, vs := range values {
+		m[k] = vs
+	}
+	return Struct(to, m, formats...)
+}
+
+// Request coerces the query parameters and form body of r into the fields
+// of the structure pointed to by 'to', as per Values
+func Request(to interface{}, r *http.Request) error {
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxFormMemory); err != nil {
+			return err
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return err
+	}
+	return Values(to, r.Form)
+}
@@ -0,0 +1,32 @@
+package coerce
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type search struct {
+	Query   string `coerce:"q"`
+	Page    int
+	Tags    []string `coerce:"tag"`
+	Verbose bool
+}
+
+func Test_Values(t *testing.T) {
+	values, _ := url.ParseQuery("q=go&page=2&tag=a&tag=b&verbose=1")
+
+	var s search
+	err := Values(&s, values)
+	report(err, search{"go", 2, []string{"a", "b"}, true}, s, t)
+}
+
+func Test_Request(t *testing.T) {
+	r := httptest.NewRequest("POST", "/search?q=go&tag=a", strings.NewReader("page=3&tag=b"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var s search
+	err := Request(&s, r)
+	report(err, search{"go", 3, []string{"b", "a"}, false}, s, t)
+}
@@ -0,0 +1,59 @@
+/**
+*  coerce is free software: you can redistribute it and/or modify
+*  it under the terms of the GNU General Public License as published by
+*  the Free Software Foundation, either version 3 of the License, or
+*  (at your option) any later version.
+*
+*  coerce is distributed in the hope that it will be useful,
+*  but WITHOUT ANY WARRANTY; without even the implied warranty of
+*  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+*  GNU General Public License for more details.
+*
+** Authors:
+ *
+ *  - Daniel <SeeSpotRun> T.   2016-2016 (https://github.com/SeeSpotRun)
+ *
+** Hosted on https://github.com/SeeSpotRun/coerce
+*
+**/
+
+package coerce
+
+import "reflect"
+
+// StringKeys returns a copy of m, as decoded by gopkg.in/yaml.v2, with its
+// keys (and those of any maps nested in it or in its lists) converted to
+// strings, so that it can be passed to Struct.  Struct and Var accept
+// nested map[interface{}]interface{} values themselves; StringKeys is only
+// needed for the top level, or where the result is kept as-is, eg in an
+// interface{} field.
+func StringKeys(m map[interface{}]interface{}) map[string]interface{} {
+	out, _ := stringKeys(m).(map[string]interface{})
+	return out
+}
+
+// stringKeys converts the map[interface{}]interface{} values in v, at any
+// depth, to map[string]interface{}
+func stringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m, _ := stringMap(reflect.ValueOf(v))
+		for k, e := range m {
+			m[k] = stringKeys(e)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = stringKeys(e)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, e := range v {
+			list[i] = stringKeys(e)
+		}
+		return list
+	}
+	return v
+}
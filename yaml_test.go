@@ -0,0 +1,61 @@
+package coerce
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Struct_yaml_v2(t *testing.T) {
+	type server struct {
+		Host string
+		Port int
+	}
+	type x struct {
+		Timeout time.Duration
+		Server  server
+		Mirrors []server
+		Limits  map[string]int
+		Codes   map[int]string
+		First   string `coerce:"/mirrors/0/host"`
+	}
+
+	doc := map[interface{}]interface{}{
+		"timeout": "5s",
+		"server":  map[interface{}]interface{}{"host": "example.com", "port": 8080},
+		"mirrors": []interface{}{
+			map[interface{}]interface{}{"host": "a.example.com", "port": "81"},
+			map[interface{}]interface{}{"host": "b.example.com", "port": 82},
+		},
+		"limits": map[interface{}]interface{}{"conns": "1k"},
+		"codes":  map[interface{}]interface{}{200: "ok", 404: "not found"},
+	}
+
+	expected := x{
+		Timeout: 5 * time.Second,
+		Server:  server{"example.com", 8080},
+		Mirrors: []server{{"a.example.com", 81}, {"b.example.com", 82}},
+		Limits:  map[string]int{"conns": 1024},
+		Codes:   map[int]string{200: "ok", 404: "not found"},
+		First:   "a.example.com",
+	}
+
+	var myx x
+	err := Struct(&myx, StringKeys(doc))
+	report(err, expected, myx, t)
+
+	var myy x
+	err = Var(&myy, doc)
+	report(err, expected, myy, t)
+}
+
+func Test_StringKeys(t *testing.T) {
+	got := StringKeys(map[interface{}]interface{}{
+		1:      "one",
+		"list": []interface{}{map[interface{}]interface{}{true: "yes"}},
+	})
+	expected := map[string]interface{}{
+		"1":    "one",
+		"list": []interface{}{map[string]interface{}{"true": "yes"}},
+	}
+	report(nil, expected, got, t)
+}